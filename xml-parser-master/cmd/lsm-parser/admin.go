@@ -0,0 +1,315 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"path/filepath"
+	"same-parser/internal/config"
+	"same-parser/internal/es"
+	"same-parser/internal/input"
+	"same-parser/internal/sink"
+	"same-parser/internal/spool"
+	"same-parser/internal/store"
+	"same-parser/internal/unmapped"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// adminAPI: 운영용 관리 HTTP API (Bearer 토큰 인증)
+// - GET  /admin/status                 처리 현황, 처리 중 파일, 채널/스풀 적체, 출력 통계, ru_mapping 적재 현황
+// - POST /admin/reprocess?path=...     파일 또는 디렉터리(하위 제외)의 입력 파일을 원장 기록과 관계없이 다시 처리
+// - POST /admin/mapping/reload         ru_mapping 즉시 재적재 (Store.Reload), 추가/삭제/변경 건수와 공급원별 결과 반환
+// - GET  /admin/mapping?ru_param=...   메모리 매핑 조회 (&at=시각 이면 그 시점에 유효했던 매핑), 버전 목록 포함
+// - GET  /admin/failures?limit=N       최근 실패 파일과 오류
+// - GET  /admin/unmapped?since=24h     매핑 누락 ru_param 집계 CSV (file_dir.unmapped_db 설정 시)
+type adminAPI struct {
+	logger    *logrus.Logger
+	cfg       *config.Config
+	jobs      *jobHandler
+	tracker   *es.Tracker
+	store     *store.Store
+	source    *store.Layered
+	misses    *unmapped.Tracker // nil 가능
+	output    sink.Sink
+	docSpool  *spool.Spool // nil 가능
+	queues    map[string]func() int
+	startedAt time.Time
+}
+
+// register: mux 에 관리 API 등록. token 이 비어 있으면 등록하지 않음.
+func (a *adminAPI) register(mux *http.ServeMux, token string) {
+	if token == "" {
+		a.logger.Warnf("http.admin_token 이 비어 있어 관리 API 비활성화")
+		return
+	}
+	auth := func(h http.HandlerFunc) http.Handler {
+		return requireToken(token, h)
+	}
+	mux.Handle("GET /admin/status", auth(a.status))
+	mux.Handle("POST /admin/reprocess", auth(a.reprocess))
+	mux.Handle("POST /admin/mapping/reload", auth(a.reloadMapping))
+	mux.Handle("GET /admin/mapping", auth(a.lookupMapping))
+	mux.Handle("GET /admin/failures", auth(a.failures))
+	mux.Handle("GET /admin/unmapped", auth(a.unmapped))
+}
+
+// requireToken: Authorization: Bearer <token> 확인
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *adminAPI) status(w http.ResponseWriter, _ *http.Request) {
+	queues := make(map[string]int, len(a.queues))
+	for name, depth := range a.queues {
+		queues[name] = depth()
+	}
+	var outputs []sink.Stats
+	sink.Each(a.output, func(s sink.Sink) {
+		outputs = append(outputs, s.Stats())
+	})
+	resp := map[string]interface{}{
+		"started_at": a.startedAt,
+		"uptime":     time.Since(a.startedAt).Round(time.Second).String(),
+		"files": map[string]int64{
+			"succeeded": a.jobs.stats.succeeded.Load(),
+			"failed":    a.jobs.stats.failed.Load(),
+			"retried":   a.jobs.stats.retried.Load(),
+			"skipped":   a.jobs.stats.skipped.Load(),
+			"docs":      a.jobs.stats.docs.Load(),
+		},
+		"in_flight": a.tracker.Snapshot(),
+		"queues":    queues,
+		"outputs":   outputs,
+		"mapping":   a.mappingStatus(a.store.Stats()),
+	}
+	if a.docSpool != nil {
+		resp["spool"] = a.docSpool.Stats()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (a *adminAPI) reprocess(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		writeError(w, http.StatusBadRequest, "path is required")
+		return
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !a.allowed(path) {
+		writeError(w, http.StatusForbidden, "path must be under scan_dir, done_dir or failed_dir")
+		return
+	}
+
+	files, err := inputFiles(path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	queued, rejected := []string{}, []string{}
+	for _, f := range files {
+		if a.jobs.reprocess(f) {
+			queued = append(queued, f)
+		} else {
+			rejected = append(rejected, f)
+		}
+	}
+	a.logger.Infof("관리 API 재처리 요청: %s (등록 %d건, 큐 가득 참 %d건)", path, len(queued), len(rejected))
+
+	code := http.StatusAccepted
+	if len(rejected) > 0 {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]interface{}{"queued": queued, "rejected": rejected})
+}
+
+func (a *adminAPI) reloadMapping(w http.ResponseWriter, _ *http.Request) {
+	diff, err := a.store.Reload(a.source)
+	if err != nil {
+		a.logger.Errorf("관리 API ruMappingMap 갱신 실패: %v", err)
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	st := a.store.Stats()
+	a.logger.Infof("관리 API ruMappingMap 갱신: %s, 행 %d, 키 %d (%s)", diff, st.Rows, st.Keys, st.Duration)
+	resp := a.mappingStatus(st)
+	resp["added"], resp["removed"], resp["changed"] = len(diff.Added), len(diff.Removed), len(diff.Changed)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (a *adminAPI) lookupMapping(w http.ResponseWriter, r *http.Request) {
+	ruParam := r.URL.Query().Get("ru_param")
+	if ruParam == "" {
+		writeError(w, http.StatusBadRequest, "ru_param is required")
+		return
+	}
+	var at time.Time
+	if v := r.URL.Query().Get("at"); v != "" {
+		t, err := parseAt(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		at = t
+	}
+	mappings, ok := a.store.GetAt(ruParam, at)
+	if !ok {
+		writeError(w, http.StatusNotFound, "ru_param not found: "+ruParam)
+		return
+	}
+	versions := []map[string]interface{}{}
+	for _, v := range a.store.Versions(ruParam) {
+		versions = append(versions, map[string]interface{}{
+			"valid_from": timeOrNil(v.ValidFrom),
+			"valid_to":   timeOrNil(v.ValidTo),
+			"mappings":   v.Mappings,
+		})
+	}
+	resp := map[string]interface{}{"ru_param": ruParam, "mappings": mappings, "versions": versions}
+	if !at.IsZero() {
+		resp["at"] = at
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseAt: 조회 시점. RFC3339 또는 "2006-01-02 15:04" (로컬 시간)
+func parseAt(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", v, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid at: %s", v)
+}
+
+// timeOrNil: zero 시각은 null 로 표시 (유효 구간 끝 없음)
+func timeOrNil(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+func (a *adminAPI) failures(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit: "+v)
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, http.StatusOK, a.jobs.recentFailures(limit))
+}
+
+func (a *adminAPI) unmapped(w http.ResponseWriter, r *http.Request) {
+	if a.misses == nil {
+		writeError(w, http.StatusNotFound, "file_dir.unmapped_db is not configured")
+		return
+	}
+	since, err := parseSince(r.URL.Query().Get("since"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// 아직 기록하지 않은 집계까지 포함
+	if err := a.misses.Flush(); err != nil {
+		a.logger.Errorf("매핑 누락 집계 기록 실패: %v", err)
+	}
+	entries, err := a.misses.List(since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="unmapped_ru_param.csv"`)
+	if err := unmapped.WriteCSV(w, entries, mappedNow(a.store)); err != nil {
+		a.logger.Errorf("매핑 누락 CSV 전송 실패: %v", err)
+	}
+}
+
+// allowed: scan_dir, done_dir, failed_dir 아래 경로인지
+func (a *adminAPI) allowed(path string) bool {
+	for _, dir := range []string{a.cfg.FileDir.ScanDir, a.cfg.FileDir.DoneDir, a.cfg.FileDir.FailedDir} {
+		if dir == "" {
+			continue
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(abs, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// inputFiles: path 가 파일이면 그 파일, 디렉터리면 바로 아래의 입력 파일 (이름 순)
+func inputFiles(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		if !input.Supported(path) {
+			return nil, fmt.Errorf("unsupported input file: %s", path)
+		}
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && input.Supported(e.Name()) {
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// mappingStatus: ru_mapping 적재 현황과 공급원별 결과
+func (a *adminAPI) mappingStatus(st store.LoadStats) map[string]interface{} {
+	return map[string]interface{}{
+		"loaded_at": st.LoadedAt,
+		"duration":  st.Duration.String(),
+		"rows":      st.Rows,
+		"keys":      st.Keys,
+		"sources":   a.source.Layers(),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
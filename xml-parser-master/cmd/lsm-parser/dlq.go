@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"same-parser/internal/config"
+	"same-parser/internal/dlq"
+	"same-parser/internal/es"
+	"same-parser/internal/logging"
+)
+
+// runDLQ: "lsm-parser dlq replay -c <config_file> [-all]" 서브커맨드.
+// dlq.dir 의 실패 항목을 ES 로 재전송하고 종료 코드 반환.
+func runDLQ(args []string) int {
+	if len(args) == 0 || args[0] != "replay" {
+		printUsage()
+	}
+
+	fs := flag.NewFlagSet("dlq replay", flag.ExitOnError)
+	configFile := fs.String("c", "", "설정 파일 경로 (예: config.yml)")
+	configFileAlias := fs.String("config", "", "설정 파일 경로 (예: config.yml)")
+	all := fs.Bool("all", false, "현재 시간 파일까지 재전송 (서비스 중지 상태에서만 사용)")
+	_ = fs.Parse(args[1:])
+
+	cfgPath := *configFile
+	if cfgPath == "" {
+		cfgPath = *configFileAlias
+	}
+	if cfgPath == "" {
+		printUsage()
+	}
+
+	cfg, err := config.LoadConfig(cfgPath)
+	if err != nil {
+		fmt.Println("Failed to load configuration file:", cfgPath, "Exiting:", err)
+		return exitError
+	}
+	if cfg.DLQ.Dir == "" {
+		fmt.Println("dlq.dir is not configured")
+		return exitError
+	}
+
+	logger, err := logging.Setup(cfg)
+	if err != nil {
+		fmt.Println("Failed to setup logging:", err)
+		return exitError
+	}
+
+	queue, err := dlq.Open(cfg.DLQ.Dir, cfg.DLQ.MaxSizeMB, cfg.DLQ.MaxAgeHours)
+	if err != nil {
+		logger.Errorf("DLQ 오픈 실패: %v", err)
+		return exitError
+	}
+	defer queue.Close()
+
+	esClient, err := es.NewClient(cfg)
+	if err != nil {
+		logger.Errorf("Elasticsearch 초기화 실패: %v", err)
+		return exitError
+	}
+	indexer, err := es.NewIndexer(logger, cfg, esClient)
+	if err != nil {
+		logger.Errorf("Elasticsearch 초기화 실패: %v", err)
+		return exitError
+	}
+
+	stats, err := dlq.Replay(context.Background(), logger, indexer, queue, *all)
+	logger.Infof("DLQ 재전송 결과: 파일=%d 항목=%d 성공=%d 재실패=%d 손상=%d",
+		stats.Files, stats.Records, stats.Succeeded, stats.Failed, stats.Malformed)
+	if err != nil {
+		logger.Errorf("DLQ 재전송 실패: %v", err)
+		return exitError
+	}
+	if stats.Failed > 0 {
+		return exitPartial
+	}
+	return exitOK
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+	"net/http"
+	"same-parser/internal/config"
+	"same-parser/internal/es"
+	"same-parser/internal/store"
+	"sync/atomic"
+	"time"
+)
+
+// healthCheck: 검사 항목 하나의 결과
+type healthCheck struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// health: /healthz(생존), /readyz(준비) 검사
+// - watcher: 파일 감시 루프가 돌고 있는지 (panic 등으로 빠져나오면 실패) — 생존/준비 공통
+// - elasticsearch: 클러스터 상태 조회 가능하고 red 가 아닌지 (출력 대상에 elasticsearch 가 있을 때)
+// - mapping: ru_mapping 이 health.mapping_max_age_hours 안에 적재되었는지
+// - files: 수집 주기(logging.collection_period) × health.stale_periods 안에 입력 파일이 들어왔는지
+type health struct {
+	cfg       *config.Config
+	esClient  *elasticsearch.Client // nil 이면 ES 검사 안 함
+	store     *store.Store
+	startedAt time.Time
+
+	watcherRunning atomic.Bool
+	lastInput      atomic.Int64 // 마지막 입력 파일 감지 시각 (UnixNano)
+}
+
+func newHealth(cfg *config.Config, esClient *elasticsearch.Client, store *store.Store) *health {
+	return &health{cfg: cfg, esClient: esClient, store: store, startedAt: time.Now()}
+}
+
+// sawInput: 입력 파일 감지 기록
+func (h *health) sawInput() {
+	h.lastInput.Store(time.Now().UnixNano())
+}
+
+func (h *health) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, map[string]healthCheck{"watcher": h.watcher()})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		checks := map[string]healthCheck{
+			"watcher": h.watcher(),
+			"mapping": h.mapping(),
+			"files":   h.files(),
+		}
+		if h.esClient != nil {
+			checks["elasticsearch"] = h.elasticsearch(r.Context())
+		}
+		writeHealth(w, checks)
+	})
+}
+
+func (h *health) watcher() healthCheck {
+	if h.watcherRunning.Load() {
+		return healthCheck{OK: true, Detail: "running"}
+	}
+	return healthCheck{Detail: "watcher loop is not running"}
+}
+
+func (h *health) elasticsearch(ctx context.Context) healthCheck {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	status, err := es.ClusterHealth(ctx, h.esClient)
+	if err != nil {
+		return healthCheck{Detail: err.Error()}
+	}
+	return healthCheck{OK: status == "green" || status == "yellow", Detail: "cluster status " + status}
+}
+
+func (h *health) mapping() healthCheck {
+	loadedAt := h.store.Stats().LoadedAt
+	if loadedAt.IsZero() {
+		return healthCheck{Detail: "ru_mapping not loaded"}
+	}
+	age := time.Since(loadedAt).Round(time.Second)
+	maxAge := time.Duration(h.cfg.Health.MappingMaxAgeHours) * time.Hour
+	if maxAge > 0 && age > maxAge {
+		return healthCheck{Detail: fmt.Sprintf("ru_mapping loaded %s ago (max %s)", age, maxAge)}
+	}
+	return healthCheck{OK: true, Detail: fmt.Sprintf("ru_mapping loaded %s ago", age)}
+}
+
+func (h *health) files() healthCheck {
+	window := h.staleAfter()
+	if window <= 0 {
+		return healthCheck{OK: true, Detail: "check disabled"}
+	}
+	last := h.startedAt
+	what := "no input since start"
+	if ns := h.lastInput.Load(); ns > 0 {
+		last = time.Unix(0, ns)
+		what = "last input"
+	}
+	since := time.Since(last).Round(time.Second)
+	if since > window {
+		return healthCheck{Detail: fmt.Sprintf("%s %s ago (expected within %s)", what, since, window)}
+	}
+	return healthCheck{OK: true, Detail: fmt.Sprintf("%s %s ago", what, since)}
+}
+
+// staleAfter: 입력이 없으면 준비 실패로 보는 시간 (0 이면 검사 안 함)
+func (h *health) staleAfter() time.Duration {
+	periods := h.cfg.Health.StalePeriods
+	if periods <= 0 || h.cfg.Logging.CollectionPeriod <= 0 {
+		return 0
+	}
+	return time.Duration(periods*h.cfg.Logging.CollectionPeriod) * time.Minute
+}
+
+func writeHealth(w http.ResponseWriter, checks map[string]healthCheck) {
+	code, status := http.StatusOK, "ok"
+	for _, c := range checks {
+		if !c.OK {
+			code, status = http.StatusServiceUnavailable, "fail"
+		}
+	}
+	writeJSON(w, code, map[string]interface{}{"status": status, "checks": checks})
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// startHTTP: addr 에서 HTTP 서버 시작. 리슨 실패는 로그만 남기고 서비스는 계속 진행.
+func startHTTP(logger *logrus.Logger, addr string, handler http.Handler) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Infof("HTTP 서버 시작: %s", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("HTTP 서버 오류: %v", err)
+		}
+	}()
+	return srv
+}
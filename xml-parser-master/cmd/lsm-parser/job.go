@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"same-parser/internal/config"
+	"same-parser/internal/es"
+	"same-parser/internal/ledger"
+	"same-parser/internal/metrics"
+	"same-parser/internal/parser"
+	"same-parser/internal/unmapped"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// jobStats: 파일 처리 누적 현황
+type jobStats struct {
+	succeeded atomic.Int64
+	failed    atomic.Int64
+	retried   atomic.Int64
+	skipped   atomic.Int64
+	docs      atomic.Int64
+}
+
+// fileJob: 처리 중인 파일 하나의 식별 정보
+type fileJob struct {
+	path string
+	size int64
+	hash string // 원장 미사용 시 빈 값
+}
+
+// jobHandler: 원장 조회, ProcessXML 결과에 따른 재시도/파일 이동/현황 집계 수행
+type jobHandler struct {
+	logger  *logrus.Logger
+	cfg     *config.Config
+	ledger  *ledger.Ledger // nil 이면 원장 사용 안 함
+	tracker *es.Tracker
+	misses  *unmapped.Tracker // nil 이면 매핑 누락 집계 안 함
+	jobChan chan<- string
+	stats   jobStats
+
+	maxRetries int
+	retryDelay time.Duration
+
+	// inFlight: 처리 중인 파일과 대기 중인 재시도. 종료 시 shutdown 이 기다림
+	inFlight sync.WaitGroup
+
+	mu       sync.Mutex
+	attempts map[string]int      // 파일별 재시도 횟수
+	force    map[string]struct{} // 원장 기록과 관계없이 다시 처리할 파일 (관리 API 재처리)
+	failures []failure           // 최근 실패 (오래된 순, 최대 maxFailures 건)
+}
+
+// maxFailures: 보관하는 최근 실패 건수
+const maxFailures = 100
+
+// failure: 최근 실패 파일 한 건
+type failure struct {
+	Path     string    `json:"path"`
+	Time     time.Time `json:"time"`
+	Error    string    `json:"error"`
+	Docs     int       `json:"docs"`
+	Failed   int       `json:"failed"`
+	Messages []string  `json:"messages,omitempty"` // XML 오류 또는 출력 실패 사유
+}
+
+func newJobHandler(logger *logrus.Logger, cfg *config.Config, l *ledger.Ledger, tracker *es.Tracker, misses *unmapped.Tracker, jobChan chan<- string) *jobHandler {
+	maxRetries := cfg.Worker.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	retryDelay := time.Duration(cfg.Worker.RetryDelaySec) * time.Second
+	if retryDelay <= 0 {
+		retryDelay = 10 * time.Second
+	}
+	return &jobHandler{
+		logger:     logger,
+		cfg:        cfg,
+		ledger:     l,
+		tracker:    tracker,
+		misses:     misses,
+		jobChan:    jobChan,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+		attempts:   make(map[string]int),
+		force:      make(map[string]struct{}),
+	}
+}
+
+// begin: 같은 파일이 이미 처리 중이거나, 원장에서 같은 내용의 파일이 이미 색인 완료되었는지 확인하고 처리 시작 기록.
+// 재처리(reprocess) 표시된 파일은 원장 조회 없이 처리.
+// skip 이 true 면 ProcessXML 을 실행하지 않음. 파일을 읽을 수 없으면 ErrOpen *ParseError 반환.
+func (h *jobHandler) begin(path string) (job *fileJob, skip bool, err error) {
+	job = &fileJob{path: path}
+	if !h.tracker.Begin(path) {
+		// 재처리 표시는 남겨 두어 처리 중인 쪽이 끝난 뒤 다시 넣은 요청에 적용
+		h.logger.Infof("이미 처리 중인 파일 건너뜀: %s", path)
+		return job, true, nil
+	}
+	force := h.forced(path)
+	metrics.FilesInFlight.Inc()
+	if h.ledger == nil {
+		return job, false, nil
+	}
+
+	job.size, job.hash, err = ledger.Fingerprint(path)
+	if err != nil {
+		return job, false, &parser.ParseError{Kind: parser.ErrOpen, File: path, Err: err}
+	}
+
+	var entry *ledger.Entry
+	if !force {
+		entry, err = h.ledger.Lookup(job.hash)
+	}
+	if err != nil {
+		h.logger.Warnf("원장 조회 실패, 처리 진행: %v", err)
+	} else if entry != nil && entry.Status == ledger.StatusIndexed {
+		h.forget(path)
+		h.tracker.Discard(path)
+		h.stats.skipped.Add(1)
+		metrics.FilesInFlight.Dec()
+		metrics.FilesProcessed.WithLabelValues("skipped").Inc()
+		h.logger.Infof("이미 색인된 파일 건너뜀: %s (기록: %s, %s, 문서 %d건)", path, entry.Path, entry.UpdatedAt, entry.DocCount)
+		h.dispose(path, h.cfg.FileDir.DoneDir)
+		return job, true, nil
+	}
+
+	if err := h.ledger.Begin(job.hash, path, job.size); err != nil {
+		h.logger.Warnf("원장 기록 실패: %v", err)
+	}
+	return job, false, nil
+}
+
+// handle: 처리 결과 반영.
+// - 재시도 가능한 오류(파일 열기/읽기 실패): retry_delay_sec 후 jobChan 에 다시 넣음 (max_retries 까지). 대기 중 종료되면 넣지 않음 (다음 실행의 백필 대상).
+// - 단, 이미 전송한 문서가 있으면 재시도 시 파일/Kafka 출력에 중복되므로 재시도하지 않고 실패 처리
+// - 그 외: 전송한 문서의 ES 응답을 모두 받은 뒤 원장 기록, 성공이면 done_dir, 실패면 failed_dir 로 이동(설정 시)
+func (h *jobHandler) handle(ctx context.Context, job *fileJob, res *parser.ParseResult, err error) {
+	if res == nil {
+		res = &parser.ParseResult{File: job.path}
+	}
+	h.stats.docs.Add(int64(res.TotalDocs()))
+	observe(res)
+
+	if err != nil && parser.IsRetryable(err) && res.TotalDocs() == 0 {
+		if n := h.nextAttempt(job.path); n <= h.maxRetries {
+			h.tracker.Discard(job.path)
+			h.stats.retried.Add(1)
+			metrics.FilesInFlight.Dec()
+			metrics.FilesProcessed.WithLabelValues("retried").Inc()
+			h.logger.Warnf("재시도 예정 (%d/%d, %s 후): %v", n, h.maxRetries, h.retryDelay, err)
+			h.retry(ctx, job.path)
+			return
+		}
+	}
+	h.forget(job.path)
+	h.recordMisses(res)
+
+	h.tracker.Seal(job.path, res.TotalDocs(), func(st es.FileStatus) {
+		h.complete(job, res, err, st)
+	})
+}
+
+// retry: retryDelay 후 path 를 jobChan 에 다시 넣는 고루틴 시작. inFlight 로 추적되어 종료 시 기다림.
+// handle 은 처리 고루틴(inFlight 에 포함) 안에서 호출되므로 여기서 Add 해도 shutdown 의 Wait 와 겹치지 않음.
+func (h *jobHandler) retry(ctx context.Context, path string) {
+	h.inFlight.Add(1)
+	go func() {
+		defer h.inFlight.Done()
+		timer := time.NewTimer(h.retryDelay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			h.logger.Warnf("종료로 재시도 취소: %s", path)
+			return
+		}
+		select {
+		case h.jobChan <- path:
+			metrics.FilesQueued.Inc()
+		case <-ctx.Done():
+			h.logger.Warnf("종료로 재시도 취소: %s", path)
+		}
+	}()
+}
+
+// complete: 완료 훅. ES 응답까지 끝난(전부 성공 또는 일부 실패) 파일의 최종 결과 기록
+func (h *jobHandler) complete(job *fileJob, res *parser.ParseResult, err error, st es.FileStatus) {
+	metrics.FilesInFlight.Dec()
+	failed := st.Failed
+	if h.ledger != nil && job.hash != "" {
+		if ledgerErr := h.ledger.Complete(job.hash, res.EndTime, res.TotalDocs(), failed, err); ledgerErr != nil {
+			h.logger.Errorf("원장 기록 실패: %v", ledgerErr)
+		}
+	}
+
+	if err == nil && failed == 0 {
+		h.stats.succeeded.Add(1)
+		metrics.FilesProcessed.WithLabelValues("succeeded").Inc()
+		h.logger.Infof("처리 완료: %s endTime=%s members=%d docs=%d unmapped=%d (%s)",
+			job.path, res.EndTime, res.Members, res.TotalDocs(), len(res.UnmappedRuParams), res.Duration)
+		h.dispose(job.path, h.cfg.FileDir.DoneDir)
+		return
+	}
+
+	h.stats.failed.Add(1)
+	metrics.FilesProcessed.WithLabelValues("failed").Inc()
+	h.recordFailure(job, res, err, st)
+	if err != nil {
+		h.logger.Errorf("처리 실패: %v (오류 %d건, 전송 문서 %d건)", err, len(res.XMLErrors), res.TotalDocs())
+		for _, e := range res.XMLErrors {
+			h.logger.Debugf("  - %v", e)
+		}
+	} else {
+		h.logger.Errorf("색인 실패: %s (문서 %d건 중 %d건 실패) %v", job.path, st.Expected, failed, st.Errors)
+	}
+	h.dispose(job.path, h.cfg.FileDir.FailedDir)
+}
+
+// observe: 파싱 소요 시간, montype 별 문서 수, 매핑 누락 ru_param 지표 반영
+func observe(res *parser.ParseResult) {
+	if res.Duration > 0 {
+		metrics.ParseDuration.Observe(res.Duration.Seconds())
+	}
+	for montype, n := range res.Docs {
+		metrics.DocsEmitted.WithLabelValues(montype).Add(float64(n))
+	}
+	metrics.RuParamMisses.Add(float64(len(res.UnmappedRuParams)))
+}
+
+// recordMisses: 매핑 누락 ru_param 집계 (재시도할 결과는 중복 집계되지 않도록 호출하지 않음)
+func (h *jobHandler) recordMisses(res *parser.ParseResult) {
+	if h.misses == nil {
+		return
+	}
+	now := time.Now()
+	for m, n := range res.Misses {
+		h.misses.Record(unmapped.Key{RuParam: m.RuParam, DU: m.ManagedElement, Montype: m.Montype}, n, now)
+	}
+}
+
+// reprocess: 원장에 색인 완료로 기록되어 있어도 다시 처리하도록 표시하고 jobChan 에 넣음. 큐가 가득 차면 false.
+func (h *jobHandler) reprocess(path string) bool {
+	h.mu.Lock()
+	h.force[path] = struct{}{}
+	h.mu.Unlock()
+	select {
+	case h.jobChan <- path:
+		metrics.FilesQueued.Inc()
+		return true
+	default:
+		h.forced(path)
+		return false
+	}
+}
+
+// forced: 재처리 표시가 있으면 지우고 true
+func (h *jobHandler) forced(path string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.force[path]
+	delete(h.force, path)
+	return ok
+}
+
+func (h *jobHandler) recordFailure(job *fileJob, res *parser.ParseResult, err error, st es.FileStatus) {
+	f := failure{Path: job.path, Time: time.Now(), Docs: res.TotalDocs(), Failed: st.Failed, Messages: st.Errors}
+	if err != nil {
+		f.Error = err.Error()
+		f.Messages = nil
+		for _, e := range res.XMLErrors {
+			f.Messages = append(f.Messages, e.Error())
+		}
+	} else {
+		f.Error = fmt.Sprintf("문서 %d건 중 %d건 출력 실패", st.Expected, st.Failed)
+	}
+
+	h.mu.Lock()
+	if len(h.failures) >= maxFailures {
+		h.failures = h.failures[1:]
+	}
+	h.failures = append(h.failures, f)
+	h.mu.Unlock()
+}
+
+// recentFailures: 최근 실패 (최신 순, 최대 limit 건)
+func (h *jobHandler) recentFailures(limit int) []failure {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if limit <= 0 || limit > len(h.failures) {
+		limit = len(h.failures)
+	}
+	out := make([]failure, 0, limit)
+	for i := len(h.failures) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, h.failures[i])
+	}
+	return out
+}
+
+func (h *jobHandler) nextAttempt(path string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.attempts[path]++
+	return h.attempts[path]
+}
+
+func (h *jobHandler) forget(path string) {
+	h.mu.Lock()
+	delete(h.attempts, path)
+	h.mu.Unlock()
+}
+
+// dispose: dir 이 설정되어 있으면 파일을 그 디렉터리로 이동
+func (h *jobHandler) dispose(path, dir string) {
+	if dir == "" {
+		return
+	}
+	if err := moveFile(path, dir); err != nil {
+		h.logger.Errorf("파일 이동 실패: %v", err)
+	}
+}
+
+// startReport: interval 마다 누적 처리 현황을 로그로 남김
+func (h *jobHandler) startReport(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			h.logger.Infof("처리 현황: 성공=%d 실패=%d 재시도=%d 중복=%d 문서=%d",
+				h.stats.succeeded.Load(), h.stats.failed.Load(), h.stats.retried.Load(), h.stats.skipped.Load(), h.stats.docs.Load())
+		}
+	}()
+}
+
+func moveFile(path, dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("mkdir %s: %w", dir, err)
+	}
+	dst := filepath.Join(dir, filepath.Base(path))
+	if err := os.Rename(path, dst); err != nil {
+		return fmt.Errorf("rename %s → %s: %w", path, dst, err)
+	}
+	return nil
+}
@@ -2,24 +2,54 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/fsnotify/fsnotify"
 	_ "modernc.org/sqlite" // SQLite3 driver
+	"net/http"
 	"os"
+	"os/signal"
+	"same-parser/internal/backfill"
 	"same-parser/internal/config"
+	"same-parser/internal/dlq"
 	"same-parser/internal/es"
+	"same-parser/internal/input"
+	"same-parser/internal/ledger"
 	"same-parser/internal/logging"
+	"same-parser/internal/metrics"
 	"same-parser/internal/model"
 	"same-parser/internal/parser"
+	"same-parser/internal/rules"
+	"same-parser/internal/sink"
+	"same-parser/internal/spool"
 	"same-parser/internal/store"
+	"same-parser/internal/unmapped"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
 func main() {
 	time.Local = time.FixedZone("KST", 9*60*60)
+	// 서브커맨드 (dlq replay, unmapped export)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "dlq":
+			os.Exit(runDLQ(os.Args[2:]))
+		case "unmapped":
+			os.Exit(runUnmapped(os.Args[2:]))
+		}
+	}
+	os.Exit(run())
+}
+
+// run: 서비스 실행. 종료 시그널(SIGTERM/SIGINT)을 받으면 정리 후 종료 코드 반환.
+func run() int {
+	startedAt := time.Now()
 	// 인자 파싱
 	configFile := flag.String("c", "", "설정 파일 경로 (예: config.yml)")
 	configFileAlias := flag.String("config", "", "설정 파일 경로 (예: config.yml)")
@@ -37,25 +67,57 @@ func main() {
 	cfg, err := config.LoadConfig(cfgPath)
 	if err != nil {
 		fmt.Println("Failed to load configuration file:", cfgPath, "Exiting:", err)
-		os.Exit(1)
+		return exitError
 	}
 
 	// 로깅
 	logger, err := logging.Setup(cfg)
 	if err != nil {
 		fmt.Println("Failed to setup logging:", err)
-		os.Exit(1)
+		return exitError
 	}
 
 	// --------------------------------------------------------------------------------
-	// Elasticsearch 인덱서 초기화
-	// - ES 클라이언트/인덱싱 관련 초기화.
+	// 벤더 파서 및 measInfo 매핑 규칙 로드
+	// - parser.vendor + elasticsearch.generation 으로 파서 선택.
+	// - parser.rules_file 이 비어 있으면 벤더/세대별 내장 기본 규칙 사용.
+	// --------------------------------------------------------------------------------
+	vendorParser, err := parser.Lookup(cfg.Vendor(), cfg.Generation())
+	if err != nil {
+		logger.Fatalf("파서 선택 실패: %v", err)
+	}
+	ruleset, err := rules.Load(cfg.Parser.RulesFile, cfg.Vendor(), cfg.Generation())
+	if err != nil {
+		logger.Fatalf("매핑 규칙 로드 실패: %v", err)
+	}
+	logger.Infof("파서: %s %s", cfg.Vendor(), cfg.Generation())
+
+	// --------------------------------------------------------------------------------
+	// Elasticsearch 클라이언트 초기화
+	// - ES 클라이언트 초기화 (색인 출력, 백필 색인 여부 조회에 사용).
 	// - 실패 시 로그 남기고 종료.
 	// --------------------------------------------------------------------------------
-	indexer, err := es.NewIndexer(cfg)
+	esClient, err := es.NewClient(cfg)
 	if err != nil {
 		logger.Fatalf("Elasticsearch 초기화 실패: %v", err)
 	}
+	// 원본 파일별 출력 응답(대기/성공/실패) 집계
+	tracker := es.NewTracker()
+
+	// --------------------------------------------------------------------------------
+	// 색인 실패 항목 DLQ (dead-letter queue)
+	// - 클라이언트 재시도 후에도 실패한 항목을 dlq.dir 에 시간별 NDJSON 으로 저장.
+	// - "dlq replay" 서브커맨드로 재전송.
+	// - dlq.dir 이 비어 있으면 사용 안 함.
+	// --------------------------------------------------------------------------------
+	var deadLetters *dlq.Queue
+	if cfg.DLQ.Dir != "" {
+		deadLetters, err = dlq.Open(cfg.DLQ.Dir, cfg.DLQ.MaxSizeMB, cfg.DLQ.MaxAgeHours)
+		if err != nil {
+			logger.Fatalf("DLQ 오픈 실패: %v", err)
+		}
+		defer deadLetters.Close()
+	}
 
 	// --------------------------------------------------------------------------------
 	// 채널 생성
@@ -67,28 +129,89 @@ func main() {
 	jobChan := make(chan string, 50000)
 
 	// --------------------------------------------------------------------------------
-	// SQLite DB 오픈 및 매핑 초기화(ENRICH 용)
-	// - SQLite 파일 경로는 설정에서 가져옴.
-	// - busy_timeout 파라미터를 통해 잠금 대기 시간 설정.
-	// - store 초기화 및 주기적 업데이트 시작.
+	// 매핑 공급원 오픈 및 매핑 초기화(ENRICH 용)
+	// - mapping.sources (SQLite, CSV, JSON 파일, HTTP) 를 순서대로 읽어 합침. 뒤 공급원이 같은 ru_param 을 덮어씀.
+	// - mapping.sources 가 비어 있으면 file_dir.sqlite_dir 의 SQLite 하나.
+	// - store 초기화 및 자동 갱신(파일 변경 감지, SIGHUP, mapping.reload_interval_min 주기) 시작.
+	// - mapping.history_db 가 있으면 재적재 때 바뀐 매핑의 이전 버전을 기록해 재시작 후에도 시점별 조회에 사용.
 	// --------------------------------------------------------------------------------
-	db, err := sql.Open("sqlite", "file:"+cfg.FileDir.SQLiteDBDir+"?_busy_timeout=5000")
+	source, err := mappingSource(cfg)
 	if err != nil {
-		logger.Fatalf("SQLite 오픈 실패: %v", err)
+		logger.Fatalf("매핑 공급원 오픈 실패: %v", err)
+	}
+	defer source.Close()
+
+	// 매핑 버전 이력: 과거 파일은 endTime 시점에 유효했던 매핑으로 보강
+	var history *store.History
+	if cfg.Mapping.HistoryDB != "" {
+		history, err = store.OpenHistory(cfg.Mapping.HistoryDB)
+		if err != nil {
+			logger.Fatalf("ru_mapping 이력 오픈 실패: %v", err)
+		}
+		defer history.Close()
 	}
-	defer db.Close()
 
 	store := store.NewStore()
-	if err := store.Init(db); err != nil {
+	if history != nil {
+		if err := store.UseHistory(history, time.Duration(cfg.Mapping.HistoryDays)*24*time.Hour); err != nil {
+			logger.Fatalf("ru_mapping 이력 적재 실패: %v", err)
+		}
+	}
+	if err := store.Init(source); err != nil {
 		logger.Fatalf("ruMappingMap 초기화 실패: %v", err)
 	}
-	//주기적 갱신 시작.
-	store.StartPeriodicUpdate(db, logger)
+	for _, l := range source.Layers() {
+		if l.Error != "" {
+			logger.Warnf("ru_mapping 공급원 적재 실패, 건너뜀 (%s): %s", l.Name, l.Error)
+			continue
+		}
+		logger.Infof("ru_mapping 공급원 %s: 행 %d, 키 %d, 덮어씀 %d", l.Name, l.Rows, l.Keys, l.Overridden)
+	}
+	// 자동 갱신 시작 (파일 변경 감지, SIGHUP, 주기)
+	reloadCfg := storeReloadConfig(cfg, source)
+	if err := store.StartAutoReload(source, logger, reloadCfg); err != nil {
+		logger.Fatalf("ruMappingMap 자동 갱신 설정 실패: %v", err)
+	}
+	logger.Infof("ruMappingMap 자동 갱신: 파일 감시=%d개 SIGHUP=%t 주기=%s", len(reloadCfg.Paths), reloadCfg.SIGHUP, reloadCfg.Interval)
+
+	// --------------------------------------------------------------------------------
+	// 처리 파일 원장(SQLite) 오픈
+	// - 파일 경로/크기/내용 해시/endTime/상태/문서 수/시각 기록.
+	// - file_dir.ledger_db 가 비어 있으면 사용 안 함.
+	// --------------------------------------------------------------------------------
+	var processed *ledger.Ledger
+	if cfg.FileDir.LedgerDB != "" {
+		processed, err = ledger.Open(cfg.FileDir.LedgerDB)
+		if err != nil {
+			logger.Fatalf("처리 원장 오픈 실패: %v", err)
+		}
+		defer processed.Close()
+	}
+
+	// --------------------------------------------------------------------------------
+	// 매핑 누락 ru_param 집계(SQLite) 오픈
+	// - ru_param/DU(managedElement)/montype 별 문서 수, 최초/최근 발생 시각을 1분마다 누적 기록.
+	// - "unmapped export" 서브커맨드 또는 관리 API(/admin/unmapped)로 CSV 내보내기.
+	// - file_dir.unmapped_db 가 비어 있으면 사용 안 함.
+	// --------------------------------------------------------------------------------
+	var misses *unmapped.Tracker
+	if cfg.FileDir.UnmappedDB != "" {
+		misses, err = unmapped.Open(cfg.FileDir.UnmappedDB)
+		if err != nil {
+			logger.Fatalf("매핑 누락 집계 오픈 실패: %v", err)
+		}
+		defer func() {
+			if err := misses.Close(); err != nil {
+				logger.Errorf("매핑 누락 집계 기록 실패: %v", err)
+			}
+		}()
+		misses.StartFlush(logger, time.Minute)
+	}
 
 	// --------------------------------------------------------------------------------
 	// 파일 감시자 설정 (fsnotify)
 	// - 특정 디렉터리를 감시하여 파일 생성 이벤트를 수신.
-	// - 이벤트에서 .xml 파일 생성만 jobChan으로 전달.
+	// - 이벤트에서 .xml 및 압축 묶음(.gz, .zip, .tar.gz, .tgz) 파일 생성만 jobChan으로 전달.
 	// --------------------------------------------------------------------------------
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -112,32 +235,247 @@ func main() {
 	sem := make(chan struct{}, maxWorkers)
 
 	// --------------------------------------------------------------------------------
-	// Elasticsearch 벌크 워커 시작
-	// - docChan에서 문서를 가져와 ES로 벌크 업로드 수행.
-	// - 내부적으로 별도의 고루틴(들)에서 동작.
+	// 처리 결과 핸들러
+	// - 원장에 색인 완료로 기록된 파일(내용 해시 기준)은 건너뜀.
+	// - ProcessXML 결과로 재시도(파일 열기 실패), ES 응답 후 원장 기록 및 done_dir/failed_dir 이동, 누적 현황 집계.
+	// - 10분마다 누적 현황 로그.
 	// --------------------------------------------------------------------------------
-	es.StartBulkWorker(logger, indexer, cfg.Elasticsearch.IndexName, docChan)
+	jobs := newJobHandler(logger, cfg, processed, tracker, misses, jobChan)
+	jobs.startReport(10 * time.Minute)
+
+	// --------------------------------------------------------------------------------
+	// 디스크 스풀 (write-ahead)
+	// - ES 장애로 출력이 막혀도 파싱이 멈추지 않도록 docChan → 스풀 → sinkChan 순으로 전달.
+	// - 평소에는 메모리로 바로 넘기고, sinkChan 이 가득 차면 세그먼트 파일에 쌓은 뒤 순서대로 전달.
+	// - ES 벌크 요청이 실패하면 그 문서를 스풀에 되돌리고, 실패가 이어지는 동안 전달을 멈춤 (1초부터 두 배씩, 최대 1분).
+	// - 재시작 시 남은 세그먼트부터 이어서 전달, 1분마다 적체 현황 로그.
+	// - spool.dir 이 비어 있으면 docChan 을 그대로 출력 워커에 연결.
+	// --------------------------------------------------------------------------------
+	sinkChan := docChan
+	var docSpool *spool.Spool
+	if cfg.Spool.Dir != "" {
+		docSpool, err = spool.Open(cfg.Spool.Dir, cfg.Spool.SegmentMB)
+		if err != nil {
+			logger.Fatalf("스풀 오픈 실패: %v", err)
+		}
+		if st := docSpool.Stats(); st.Docs > 0 {
+			logger.Infof("이전 실행에서 남은 스풀: 세그먼트=%d 문서=%d", st.Segments, st.Docs)
+		}
+		sinkChan = make(chan model.ElasticDocument, 5000)
+		docSpool.Run(logger, docChan, sinkChan)
+		docSpool.StartReport(logger, time.Minute)
+	}
+
+	// --------------------------------------------------------------------------------
+	// 출력 대상(sink) 구성 및 출력 워커 시작
+	// - sink.outputs 순서대로 elasticsearch(벌크 색인), file(NDJSON 회전 파일), kafka(토픽 발행)를 조합.
+	// - 파일별 응답 집계(tracker)는 맨 앞 출력 대상의 응답(ES 색인, 파일 flush/회전, Kafka 브로커 ack) 기준.
+	// - sinkChan에서 문서를 가져와 출력, sinkChan 이 닫히면 sinkDone 이 닫힘.
+	// --------------------------------------------------------------------------------
+	// ES 벌크 요청 자체가 실패한 문서는 스풀이 있으면 스풀로 되돌려 ES 가 회복된 뒤 다시 보냄 (없으면 DLQ)
+	var requeue es.Requeuer
+	if docSpool != nil {
+		requeue = docSpool
+	}
+	var sinks sink.Multi
+	for i, name := range cfg.Outputs() {
+		var acker sink.Acker
+		if i == 0 {
+			acker = tracker
+		}
+		switch name {
+		case "elasticsearch":
+			indexer, err := es.NewIndexer(logger, cfg, esClient)
+			if err != nil {
+				logger.Fatalf("Elasticsearch 초기화 실패: %v", err)
+			}
+			metrics.RegisterBulkIndexer(indexer)
+			var bulkTracker *es.Tracker
+			if i == 0 {
+				bulkTracker = tracker
+			}
+			sinks = append(sinks, es.NewBulkSink(logger, indexer, cfg.Elasticsearch.IndexName, bulkTracker, deadLetters, requeue))
+		case "file":
+			fileSink, err := sink.NewFile(logger, sink.FileConfig{
+				Dir:         cfg.Sink.File.Dir,
+				Prefix:      cfg.Sink.File.Prefix,
+				MaxBytes:    int64(cfg.Sink.File.MaxSizeMB) << 20,
+				RotateEvery: time.Duration(cfg.Sink.File.RotateMinutes) * time.Minute,
+				Gzip:        cfg.Sink.File.Gzip,
+			}, acker)
+			if err != nil {
+				logger.Fatalf("파일 출력 초기화 실패: %v", err)
+			}
+			sinks = append(sinks, fileSink)
+		case "kafka":
+			k := cfg.Sink.Kafka
+			kafkaSink, err := sink.NewKafka(logger, sink.KafkaConfig{
+				Brokers:         k.Brokers,
+				Topic:           k.Topic,
+				Key:             k.Key,
+				BatchSize:       k.BatchSize,
+				BatchBytes:      k.BatchBytes,
+				Linger:          time.Duration(k.LingerMs) * time.Millisecond,
+				Compression:     k.Compression,
+				RequiredAcks:    k.RequiredAcks,
+				MaxAttempts:     k.MaxAttempts,
+				AutoCreateTopic: k.AutoCreateTopic,
+			}, acker)
+			if err != nil {
+				logger.Fatalf("Kafka 출력 초기화 실패: %v", err)
+			}
+			sinks = append(sinks, kafkaSink)
+		default:
+			logger.Fatalf("알 수 없는 출력 대상: %s", name)
+		}
+	}
+	var output sink.Sink = sinks
+	if len(sinks) == 1 {
+		output = sinks[0]
+	}
+	logger.Infof("출력 대상: %v", cfg.Outputs())
+	sinkDone := sink.Start(logger, output, sinkChan)
+
+	// --------------------------------------------------------------------------------
+	// HTTP 서버 (Prometheus 메트릭, 관리 API)
+	// - /metrics: 감시 이벤트, 파일 처리 현황, 파싱 시간, montype 별 문서 수, 매핑 누락,
+	//   채널 적체, 벌크 인덱서 통계, ru_mapping 적재 현황.
+	// - /healthz: 감시 루프 생존, /readyz: 감시 루프 + ES 클러스터 상태 + ru_mapping 적재 시점 + 입력 파일 유입.
+	// - /admin/: 현황 조회, 파일/디렉터리 재처리, ru_mapping 즉시 갱신/조회, 최근 실패 (http.admin_token 인증).
+	// - http.listen 이 비어 있으면 사용 안 함.
+	// --------------------------------------------------------------------------------
+	metrics.QueueDepth("doc", func() int { return len(docChan) }, func() int { return cap(docChan) })
+	metrics.QueueDepth("job", func() int { return len(jobChan) }, func() int { return cap(jobChan) })
+	if docSpool != nil {
+		metrics.QueueDepth("sink", func() int { return len(sinkChan) }, func() int { return cap(sinkChan) })
+	}
+	metrics.RegisterStore(store)
+	var healthES *elasticsearch.Client
+	if slices.Contains(cfg.Outputs(), "elasticsearch") {
+		healthES = esClient
+	}
+	probe := newHealth(cfg, healthES, store)
+	if cfg.HTTP.Listen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		probe.register(mux)
+		admin := &adminAPI{
+			logger:   logger,
+			cfg:      cfg,
+			jobs:     jobs,
+			tracker:  tracker,
+			store:    store,
+			source:   source,
+			misses:   misses,
+			output:   output,
+			docSpool: docSpool,
+			queues: map[string]func() int{
+				"doc": func() int { return len(docChan) },
+				"job": func() int { return len(jobChan) },
+			},
+			startedAt: startedAt,
+		}
+		if docSpool != nil {
+			admin.queues["sink"] = func() int { return len(sinkChan) }
+		}
+		admin.register(mux, cfg.HTTP.AdminToken)
+		srv := startHTTP(logger, cfg.HTTP.Listen, mux)
+		defer srv.Close()
+	}
+
+	// --------------------------------------------------------------------------------
+	// 종료 시그널 (SIGTERM/SIGINT)
+	// - ctx 가 취소되면 감시/작업 소비를 멈추고 shutdown 에서 순서대로 정리.
+	// --------------------------------------------------------------------------------
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	// --------------------------------------------------------------------------------
+	// 시작 시 백필 (goroutine)
+	// - 중단된 동안 scan_dir 에 쌓인 파일을 endTime(또는 mtime) 순으로 jobChan에 전달.
+	// - 스캔(헤더 확인, 문서 수 계산, ES 조회)은 감시 루프와 따로 실행되므로 스캔 중 생성된 파일도 바로 큐에 들어감.
+	// - 감시는 백필 전에 등록되어 있어 스캔 직전/도중 생성된 파일은 목록과 이벤트에 모두 나올 수 있으므로,
+	//   백필이 끝나고 1분 동안만 먼저 넣은 쪽 기준으로 중복을 거름.
+	// - backfill.skip_indexed 는 elasticsearch 출력이 있을 때만 적용 (파일이 만들 문서 수만큼 색인된 파일 제외).
+	// --------------------------------------------------------------------------------
+	dedup := &startupDedup{}
+	if cfg.Backfill.Enabled {
+		dedup = newStartupDedup()
+		var expected backfill.ExpectedFunc
+		var indexed backfill.IndexedFunc
+		if cfg.Backfill.SkipIndexed {
+			if slices.Contains(cfg.Outputs(), "elasticsearch") {
+				expected = backfill.Counter(logger, cfg, ruleset, vendorParser, store)
+				indexed = func(equipID, measDate string) (int, error) {
+					ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+					defer cancel()
+					return es.CountIndexed(ctx, esClient, cfg.Elasticsearch.IndexName, equipID, measDate)
+				}
+			} else {
+				logger.Warnf("elasticsearch 출력이 없어 backfill.skip_indexed 무시")
+			}
+		}
+		go func() {
+			defer dedup.expire(time.Minute)
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorf("Backfill goroutine panic: %v", r)
+				}
+			}()
+			paths, err := backfill.Scan(logger, cfg, vendorParser, expected, indexed)
+			if err != nil {
+				logger.Errorf("백필 실패: %v", err)
+			}
+			for _, p := range paths {
+				if !dedup.claim(p) {
+					continue
+				}
+				metrics.FilesQueued.Inc()
+				probe.sawInput()
+				select {
+				case jobChan <- p:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
 
 	// --------------------------------------------------------------------------------
 	// 파일 감시 루프 (goroutine)
-	// - fsnotify 이벤트를 받아 .xml 파일 생성 이벤트를 감지하여 jobChan에 전달.
+	// - fsnotify 이벤트를 받아 입력 파일 생성 이벤트를 감지하여 jobChan에 전달.
 	// - watcher.Errors 채널을 모니터링하여 에러 로깅 수행.
 	// - recover로 panic 방지 및 로그 기록.
 	// --------------------------------------------------------------------------------
 	go func() {
+		probe.watcherRunning.Store(true)
+		defer probe.watcherRunning.Store(false)
 		defer func() {
 			if r := recover(); r != nil {
 				logger.Errorf("Watcher goroutine panic: %v", r)
 			}
 		}()
+
 		for {
 			select {
+			case <-ctx.Done():
+				return
 			case event, ok := <-watcher.Events:
 				if !ok {
 					return
 				}
-				if event.Op&fsnotify.Create != 0 && strings.HasSuffix(strings.ToLower(event.Name), ".xml") {
-					jobChan <- event.Name
+				metrics.FsEvents.WithLabelValues(event.Op.String()).Inc()
+				if event.Op&fsnotify.Create != 0 && input.Supported(event.Name) {
+					if !dedup.claim(event.Name) {
+						continue
+					}
+					metrics.FilesQueued.Inc()
+					probe.sawInput()
+					select {
+					case jobChan <- event.Name:
+					case <-ctx.Done():
+						return
+					}
 				}
 			case watcherErr, ok := <-watcher.Errors:
 				if !ok {
@@ -153,31 +491,178 @@ func main() {
 	// - jobChan으로부터 파일 경로를 받아 비동기 처리.
 	// - sem 채널을 통해 동시 실행 수 제한.
 	// - waitStable로 파일이 완전히 업로드/작성되어 안정된 상태인지 검사 후 파서 실행.
+	// - 종료 시그널을 받으면 새 작업은 받지 않음 (jobChan 에 남은 파일은 다음 실행의 백필 대상).
 	// --------------------------------------------------------------------------------
-	for path := range jobChan {
-		sem <- struct{}{}
+consume:
+	for {
+		var path string
+		select {
+		case <-ctx.Done():
+			break consume
+		case path = <-jobChan:
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break consume
+		}
+		jobs.inFlight.Add(1)
 		go func(p string) {
+			defer jobs.inFlight.Done()
 			defer func() { <-sem }()
 			if stableErr := waitStable(p, 2*time.Second); stableErr != nil {
+				if errors.Is(stableErr, errNotStable) {
+					metrics.StableTimeouts.Inc()
+				}
 				logger.Errorf("안정화 오류: %v", stableErr)
 				return
 			}
 			logger.Debugf("✅ 안정화 완료: %s", p)
-			parser.ProcessXML(logger, cfg, store, p, docChan)
+			job, skip, err := jobs.begin(p)
+			if err != nil {
+				jobs.handle(ctx, job, nil, err)
+				return
+			}
+			if skip {
+				return
+			}
+			res, err := parser.ProcessXML(logger, cfg, ruleset, vendorParser, store, p, docChan)
+			jobs.handle(ctx, job, res, err)
 		}(path)
 	}
 
-	_ = context.Background()
+	// --------------------------------------------------------------------------------
+	// 정상 종료
+	// - 감시 중지 → 처리 중인 파일 대기 → docChan 닫기 → 스풀/출력 워커 비우기 → 출력 flush/close.
+	// - 전체 과정은 worker.shutdown_timeout_sec 안에 끝나야 하며, 넘으면 exitTimeout.
+	// --------------------------------------------------------------------------------
+	logger.Infof("종료 시그널 수신, 정리 시작")
+	watcher.Close()
+	return shutdown(logger, cfg, &jobs.inFlight, docChan, docSpool, sinkDone, output, tracker)
+}
+
+// mappingSource: mapping.sources → 공급원 (설정 순서대로 겹침, 비어 있으면 file_dir.sqlite_dir)
+func mappingSource(cfg *config.Config) (*store.Layered, error) {
+	attrs := store.Attributes{
+		Columns:   cfg.Mapping.Attributes.Columns,
+		Latitude:  cfg.Mapping.Attributes.Latitude,
+		Longitude: cfg.Mapping.Attributes.Longitude,
+	}
+	if err := attrs.Validate(); err != nil {
+		return nil, err
+	}
+
+	var layers []*store.Layer
+	fail := func(err error) (*store.Layered, error) {
+		store.NewLayered(layers...).Close()
+		return nil, err
+	}
+	for i, sc := range cfg.Mapping.Sources {
+		var src store.Source
+		switch strings.ToLower(strings.TrimSpace(sc.Type)) {
+		case "", "sqlite":
+			path := sc.Path
+			if path == "" {
+				path = cfg.FileDir.SQLiteDBDir
+			}
+			sqlite, err := store.NewSQLite(path, attrs)
+			if err != nil {
+				return fail(err)
+			}
+			src = sqlite
+		case "csv":
+			if sc.Path == "" {
+				return fail(fmt.Errorf("mapping.sources[%d]: path is required", i))
+			}
+			src = store.NewCSV(sc.Path, attrs)
+		case "json":
+			if sc.Path == "" {
+				return fail(fmt.Errorf("mapping.sources[%d]: path is required", i))
+			}
+			src = store.NewJSON(sc.Path, attrs)
+		case "http":
+			if sc.URL == "" {
+				return fail(fmt.Errorf("mapping.sources[%d]: url is required", i))
+			}
+			src = store.NewHTTP(sc.URL, sc.Headers, time.Duration(sc.TimeoutSec)*time.Second, attrs)
+		default:
+			return fail(fmt.Errorf("mapping.sources[%d]: unknown type %q", i, sc.Type))
+		}
+		layers = append(layers, &store.Layer{Source: src, Optional: sc.Optional})
+	}
+	if len(layers) == 0 {
+		sqlite, err := store.NewSQLite(cfg.FileDir.SQLiteDBDir, attrs)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, &store.Layer{Source: sqlite})
+	}
+	return store.NewLayered(layers...), nil
+}
+
+// storeReloadConfig: mapping 설정 → ru_mapping 자동 갱신 설정
+func storeReloadConfig(cfg *config.Config, source *store.Layered) store.ReloadConfig {
+	rc := store.ReloadConfig{
+		Interval: cfg.ReloadInterval(),
+		Debounce: time.Duration(cfg.Mapping.DebounceSec) * time.Second,
+		SIGHUP:   cfg.Mapping.SIGHUP,
+	}
+	if cfg.Mapping.Watch {
+		rc.Paths = source.Paths()
+	}
+	return rc
 }
 
 func printUsage() {
 	usage := `Usage: fetch-xml-files -c <config_file>
+       fetch-xml-files dlq replay -c <config_file> [-all]
+       fetch-xml-files unmapped export -c <config_file> [-o <csv_file>] [-since <duration>]
  -c, --config    설정 파일 경로 (예: config.yml)
+ -all            (dlq replay) 현재 시간 파일까지 재전송, 서비스 중지 상태에서만 사용
+ -o              (unmapped export) CSV 저장 경로 (비우면 표준 출력)
+ -since          (unmapped export) 최근 발생 시각이 이 기간 안인 항목만 (예: 24h, 비우면 전체)
 `
 	fmt.Print(usage)
-	os.Exit(1)
+	os.Exit(exitError)
+}
+
+// startupDedup: 시작 직후 백필 목록과 감시 이벤트에 함께 나온 파일을 한 번만 큐에 넣기 위한 집합.
+// expire 이후에는 기록하지 않고 모두 통과 (같은 이름으로 다시 생성된 파일은 새 파일로 처리).
+type startupDedup struct {
+	mu   sync.Mutex
+	seen map[string]struct{} // nil 이면 만료(또는 백필 미사용)
+}
+
+func newStartupDedup() *startupDedup {
+	return &startupDedup{seen: make(map[string]struct{})}
+}
+
+// claim: 처음 넣는 경로면 기록하고 true, 이미 넣은 경로면 false
+func (d *startupDedup) claim(path string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.seen == nil {
+		return true
+	}
+	if _, ok := d.seen[path]; ok {
+		return false
+	}
+	d.seen[path] = struct{}{}
+	return true
+}
+
+// expire: after 가 지나면 집합을 비움
+func (d *startupDedup) expire(after time.Duration) {
+	time.AfterFunc(after, func() {
+		d.mu.Lock()
+		d.seen = nil
+		d.mu.Unlock()
+	})
 }
 
+// errNotStable: waitStable 제한 시간 안에 크기가 안정되지 않음
+var errNotStable = errors.New("파일 안정화 타임아웃")
+
 // waitStable: 파일이 일정 시간 동안 크기 변동이 없을 때까지 대기.
 func waitStable(name string, stableDur time.Duration) error {
 	var prevSize int64 = -1
@@ -206,7 +691,7 @@ func waitStable(name string, stableDur time.Duration) error {
 				stableStart = time.Time{}
 			}
 		case <-timeout:
-			return fmt.Errorf("파일 안정화 타임아웃: %s", name)
+			return fmt.Errorf("%w: %s", errNotStable, name)
 		}
 	}
 }
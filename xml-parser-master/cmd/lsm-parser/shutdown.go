@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"same-parser/internal/config"
+	"same-parser/internal/es"
+	"same-parser/internal/model"
+	"same-parser/internal/sink"
+	"same-parser/internal/spool"
+	"sync"
+	"time"
+)
+
+// 종료 코드
+const (
+	exitOK      = 0 // 모든 문서 색인 완료
+	exitError   = 1 // 설정/초기화 오류
+	exitPartial = 2 // 일부 문서 색인 실패 (DLQ 사용 시 DLQ 에 기록됨)
+	exitTimeout = 3 // 제한 시간 안에 정리하지 못함 (처리 중 파일/버퍼 문서 유실 가능)
+)
+
+// minCloseTimeout: 출력 flush/close 에 주는 최소 시간. 앞 단계에서 제한 시간을 다 써도 이만큼은 따로 기다림.
+const minCloseTimeout = 10 * time.Second
+
+// shutdown: 종료 시그널 이후 정리.
+// 처리 중인 파일 대기 → docChan 닫기 → 스풀 비우기 → 출력 워커 종료 대기 → 출력 flush/close 순으로 진행.
+// 스풀이 제한 시간 안에 비워지지 않으면 남은 문서는 디스크에 두고(다음 실행에서 전달) 계속 진행.
+// 어느 단계에서 시간이 초과되어도 마지막 flush/close 는 별도 제한 시간(남은 시간, 최소 minCloseTimeout)으로 실행.
+func shutdown(
+	logger *logrus.Logger,
+	cfg *config.Config,
+	inFlight *sync.WaitGroup,
+	docChan chan model.ElasticDocument,
+	docSpool *spool.Spool,
+	sinkDone <-chan struct{},
+	output sink.Sink,
+	tracker *es.Tracker,
+) int {
+	timeout := time.Duration(cfg.Worker.ShutdownTimeoutSec) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 1. 처리 중인 파일 대기.
+	// 시간 초과 시 docChan 을 닫으면 전송 중인 고루틴이 panic 하므로 닫지 않고 2~4 단계를 건너뜀.
+	// 이때 처리 중이던 파일의 나머지 문서는 유실되며, 그 파일은 완료 처리되지 않아 scan_dir 에 남음 (다음 실행에서 재처리).
+	// 이미 넘어온 문서는 스풀(있으면)에 보존하고 출력 버퍼는 5 단계에서 flush.
+	code := exitOK
+	inputClosed := true
+	workersDone := make(chan struct{})
+	go func() {
+		inFlight.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+		logger.Infof("처리 중인 파일 완료")
+	case <-ctx.Done():
+		logger.Errorf("처리 중인 파일 대기 시간 초과 (%s), 처리 중이던 파일의 남은 문서는 유실", timeout)
+		code = exitTimeout
+		inputClosed = false
+	}
+
+	// 2. 더 이상 문서 없음
+	if inputClosed {
+		close(docChan)
+	}
+
+	// 3. 스풀 비우기 (ES 가 계속 막혀 있으면 남은 문서는 디스크에 보존)
+	if docSpool != nil {
+		select {
+		case <-docSpool.Done():
+		case <-ctx.Done():
+			docSpool.Stop()
+			st := docSpool.Stats()
+			logger.Warnf("스풀 비우기 시간 초과, 다음 실행에서 전달: 세그먼트=%d 문서=%d", st.Segments, st.Docs)
+		}
+	}
+
+	// 4. 출력 워커가 마지막 문서까지 넘길 때까지 대기 (입력이 닫히지 않았으면 끝나지 않으므로 건너뜀)
+	workerEnds := inputClosed || docSpool != nil
+	if workerEnds {
+		select {
+		case <-sinkDone:
+		case <-ctx.Done():
+			logger.Errorf("출력 워커 종료 대기 시간 초과 (%s)", timeout)
+			code = exitTimeout
+		}
+	}
+
+	// 5. 남은 버퍼 flush 및 출력 종료 (ES 는 BulkIndexerStats 로그, 완료 훅이 여기서 실행될 수 있음).
+	// 앞 단계의 제한 시간과 별도로 기다려, 시간 초과 후에도 이미 받은 문서는 내보냄.
+	deadline, _ := ctx.Deadline()
+	closeTimeout := time.Until(deadline)
+	if closeTimeout < minCloseTimeout {
+		closeTimeout = minCloseTimeout
+	}
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer closeCancel()
+	if err := output.Close(closeCtx); err != nil {
+		logger.Errorf("출력 종료 실패: %v", err)
+		code = exitTimeout
+	}
+
+	// 6. 출력 워커가 아직 문서를 넘기고 있으면 마저 넘길 때까지 대기.
+	// 종료된 출력은 Write 를 거절하므로 ES 문서는 스풀(있으면) 또는 DLQ 로 빠르게 정리됨.
+	if workerEnds {
+		select {
+		case <-sinkDone:
+		case <-closeCtx.Done():
+			logger.Errorf("출력 워커 종료 대기 시간 초과, 남은 문서 유실")
+		}
+	}
+
+	var failed uint64
+	sink.Each(output, func(s sink.Sink) {
+		st := s.Stats()
+		failed += st.Failed
+		logger.Infof("출력 통계 [%s]: written=%d succeeded=%d failed=%d", st.Name, st.Written, st.Succeeded, st.Failed)
+	})
+	logUnfinished(logger, tracker)
+
+	if code == exitOK && failed > 0 {
+		code = exitPartial
+	}
+	logger.Infof("종료 (code=%d)", code)
+	return code
+}
+
+// logUnfinished: ES 응답을 다 받지 못한 파일 목록
+func logUnfinished(logger *logrus.Logger, tracker *es.Tracker) {
+	for _, st := range tracker.Snapshot() {
+		logger.Warnf("미완료 파일: %s (대기 %d, 성공 %d, 실패 %d)", st.File, st.Pending, st.Succeeded, st.Failed)
+	}
+}
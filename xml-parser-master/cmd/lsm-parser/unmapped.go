@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"same-parser/internal/config"
+	"same-parser/internal/store"
+	"same-parser/internal/unmapped"
+	"time"
+)
+
+// runUnmapped: "lsm-parser unmapped export -c <config_file> [-o <csv_file>] [-since <duration>]" 서브커맨드.
+// file_dir.unmapped_db 의 매핑 누락 ru_param 집계를 CSV 로 내보내고 종료 코드 반환.
+// 실행 중인 서비스가 아직 기록하지 않은 집계(최대 1분)는 포함되지 않음.
+func runUnmapped(args []string) int {
+	if len(args) == 0 || args[0] != "export" {
+		printUsage()
+	}
+
+	fs := flag.NewFlagSet("unmapped export", flag.ExitOnError)
+	configFile := fs.String("c", "", "설정 파일 경로 (예: config.yml)")
+	configFileAlias := fs.String("config", "", "설정 파일 경로 (예: config.yml)")
+	out := fs.String("o", "", "CSV 저장 경로 (비우면 표준 출력)")
+	sinceFlag := fs.String("since", "", "최근 발생 시각이 이 기간 안인 항목만 (예: 24h)")
+	_ = fs.Parse(args[1:])
+
+	cfgPath := *configFile
+	if cfgPath == "" {
+		cfgPath = *configFileAlias
+	}
+	if cfgPath == "" {
+		printUsage()
+	}
+
+	cfg, err := config.LoadConfig(cfgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to load configuration file:", cfgPath, "Exiting:", err)
+		return exitError
+	}
+	if cfg.FileDir.UnmappedDB == "" {
+		fmt.Fprintln(os.Stderr, "file_dir.unmapped_db is not configured")
+		return exitError
+	}
+	since, err := parseSince(*sinceFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	misses, err := unmapped.Open(cfg.FileDir.UnmappedDB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "매핑 누락 집계 오픈 실패:", err)
+		return exitError
+	}
+	defer misses.Close()
+
+	entries, err := misses.List(since)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	// 현재 ru_mapping 에 추가되었는지 (mapped_now) 표시, 매핑 DB 를 읽을 수 없으면 생략
+	var mapped func(string) bool
+	if source, err := mappingSource(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "매핑 공급원 오픈 실패, mapped_now 생략:", err)
+	} else {
+		defer source.Close()
+		st := store.NewStore()
+		if err := st.Init(source); err != nil {
+			fmt.Fprintln(os.Stderr, "ru_mapping 적재 실패, mapped_now 생략:", err)
+		} else {
+			mapped = mappedNow(st)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := unmapped.WriteCSV(w, entries, mapped); err != nil {
+		fmt.Fprintln(os.Stderr, "CSV 저장 실패:", err)
+		return exitError
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "매핑 누락 ru_param %d건 저장: %s\n", len(entries), *out)
+	}
+	return exitOK
+}
+
+// parseSince: "24h" 같은 기간 → 기준 시각, 비어 있으면 zero (전체)
+func parseSince(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid since: %s", v)
+	}
+	return time.Now().Add(-d), nil
+}
+
+// mappedNow: 현재 ru_mapping 에 있는 ru_param 인지
+func mappedNow(st *store.Store) func(string) bool {
+	return func(ruParam string) bool {
+		_, ok := st.Get(ruParam)
+		return ok
+	}
+}
@@ -0,0 +1,205 @@
+package backfill
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"same-parser/internal/config"
+	"same-parser/internal/input"
+	"same-parser/internal/model"
+	"same-parser/internal/parser"
+	"same-parser/internal/rules"
+	"same-parser/internal/store"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Group: 색인 여부 조회 단위 (equip_id + measdate(200601021504))
+type Group struct {
+	EquipID  string
+	MeasDate string
+}
+
+// IndexedFunc: equip_id + measdate 로 이미 색인된 문서 수 조회
+type IndexedFunc func(equipID, measDate string) (int, error)
+
+// ExpectedFunc: 파일을 처리하면 만들어질 문서 수 (Group 별)
+type ExpectedFunc func(path string) (map[Group]int, error)
+
+// candidate: 백필 대상 파일
+type candidate struct {
+	path    string
+	modTime time.Time
+	endTime time.Time // 알 수 없으면 zero → modTime 으로 정렬
+}
+
+func (c candidate) sortKey(byEndTime bool) time.Time {
+	if byEndTime && !c.endTime.IsZero() {
+		return c.endTime
+	}
+	return c.modTime
+}
+
+// Scan: scan_dir 에 이미 있는 입력 파일 중 처리할 파일 목록을 정렬해 반환.
+// - backfill.max_age_hours 보다 오래된 파일(mtime 기준)은 제외 (0 이면 제한 없음)
+// - backfill.order 가 endtime 이면 파일의 endTime 순, mtime 이면 수정 시각 순
+// - expected, indexed 가 모두 nil 이 아니면 파일이 만들 문서가 (equip_id, measdate) 별로 모두 색인된 파일은 제외 (일부만 색인된 파일은 다시 처리)
+func Scan(logger *logrus.Logger, cfg *config.Config, vp parser.Parser, expected ExpectedFunc, indexed IndexedFunc) ([]string, error) {
+	entries, err := os.ReadDir(cfg.FileDir.ScanDir)
+	if err != nil {
+		return nil, fmt.Errorf("read scan_dir: %w", err)
+	}
+
+	var cutoff time.Time
+	if cfg.Backfill.MaxAgeHours > 0 {
+		cutoff = time.Now().Add(-time.Duration(cfg.Backfill.MaxAgeHours) * time.Hour)
+	}
+	byEndTime := !strings.EqualFold(cfg.Backfill.Order, "mtime")
+
+	var files []candidate
+	tooOld, done := 0, 0
+	for _, entry := range entries {
+		if entry.IsDir() || !input.Supported(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			logger.Warnf("백필: 파일 정보 조회 실패: %v", err)
+			continue
+		}
+		if !cutoff.IsZero() && info.ModTime().Before(cutoff) {
+			tooOld++
+			continue
+		}
+
+		c := candidate{path: filepath.Join(cfg.FileDir.ScanDir, entry.Name()), modTime: info.ModTime()}
+		if byEndTime {
+			heads, err := peek(c.path, vp)
+			if err != nil {
+				logger.Warnf("백필: 헤더 확인 실패, 처리 대상에 포함: %s %v", c.path, err)
+			} else {
+				c.endTime = earliest(heads)
+			}
+		}
+		if expected != nil && indexed != nil && allIndexed(logger, c.path, expected, indexed) {
+			done++
+			continue
+		}
+		files = append(files, c)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		ti, tj := files[i].sortKey(byEndTime), files[j].sortKey(byEndTime)
+		if ti.Equal(tj) {
+			return files[i].path < files[j].path
+		}
+		return ti.Before(tj)
+	})
+
+	paths := make([]string, len(files))
+	for i, c := range files {
+		paths[i] = c.path
+	}
+	logger.Infof("백필 대상: %d건 (기간 초과 제외 %d건, 색인 완료 제외 %d건)", len(paths), tooOld, done)
+	return paths, nil
+}
+
+// head: 파일(묶음이면 멤버) 하나의 식별 정보
+type head struct {
+	endTime time.Time
+}
+
+// peek: measInfo 는 디코딩하지 않고 멤버별 endTime 만 읽음
+func peek(path string, vp parser.Parser) ([]head, error) {
+	var heads []head
+	memberErrs, err := input.Walk(path, func(name string, r io.Reader) error {
+		mc, err := vp.Parse(r, func(string) bool { return false })
+		if err != nil {
+			return err
+		}
+		t, err := time.Parse(time.RFC3339, mc.EndTime)
+		if err != nil {
+			return fmt.Errorf("endTime: %w", err)
+		}
+		heads = append(heads, head{endTime: t})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(memberErrs) > 0 {
+		return nil, memberErrs[0]
+	}
+	if len(heads) == 0 {
+		return nil, fmt.Errorf("no xml member")
+	}
+	return heads, nil
+}
+
+func earliest(heads []head) time.Time {
+	var t time.Time
+	for _, h := range heads {
+		if t.IsZero() || h.endTime.Before(t) {
+			t = h.endTime
+		}
+	}
+	return t
+}
+
+// allIndexed: 파일이 만들 문서 수만큼 (equip_id, measdate) 별로 모두 색인되어 있는지.
+// 문서를 만들지 않는 파일, 계산/조회 실패 시 false → 다시 처리.
+func allIndexed(logger *logrus.Logger, path string, expected ExpectedFunc, indexed IndexedFunc) bool {
+	want, err := expected(path)
+	if err != nil || len(want) == 0 {
+		if err != nil {
+			logger.Warnf("백필: 문서 수 계산 실패, 처리 대상에 포함: %s %v", path, err)
+		}
+		return false
+	}
+	for g, n := range want {
+		got, err := indexed(g.EquipID, g.MeasDate)
+		if err != nil {
+			logger.Warnf("백필: 색인 여부 조회 실패: %s %s %v", g.EquipID, g.MeasDate, err)
+			return false
+		}
+		if got < n {
+			if got > 0 {
+				logger.Infof("백필: 일부만 색인된 파일 다시 처리: %s (%s %s 색인 %d/%d건)", path, g.EquipID, g.MeasDate, got, n)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// Counter: 파일을 규칙대로 파싱해 만들어질 문서 수를 세는 ExpectedFunc (출력 대상에는 보내지 않음)
+func Counter(logger *logrus.Logger, cfg *config.Config, rs *rules.Ruleset, vp parser.Parser, st *store.Store) ExpectedFunc {
+	return func(path string) (map[Group]int, error) {
+		docChan := make(chan model.ElasticDocument, 1024)
+		counts := make(map[Group]int)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for doc := range docChan {
+				counts[Group{EquipID: deref(doc.EquipID), MeasDate: deref(doc.MeasDate)}]++
+			}
+		}()
+		_, err := parser.ProcessXML(logger, cfg, rs, vp, st, path, docChan)
+		close(docChan)
+		<-done
+		if err != nil {
+			return nil, err
+		}
+		return counts, nil
+	}
+}
+
+func deref(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
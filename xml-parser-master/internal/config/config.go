@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"os"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -16,7 +18,11 @@ type Config struct {
 	} `yaml:"elasticsearch"`
 	FileDir struct {
 		ScanDir     string `yaml:"scan_dir"`
-		SQLiteDBDir string `yaml:"sqlite_dir"` // 예: "/remote/du"
+		SQLiteDBDir string `yaml:"sqlite_dir"`  // 예: "/remote/du"
+		DoneDir     string `yaml:"done_dir"`    // 처리 완료 파일 이동 경로 (비우면 그대로 둠)
+		FailedDir   string `yaml:"failed_dir"`  // 처리 실패 파일 격리 경로 (비우면 그대로 둠)
+		LedgerDB    string `yaml:"ledger_db"`   // 처리 파일 원장 SQLite 경로 (비우면 사용 안 함)
+		UnmappedDB  string `yaml:"unmapped_db"` // 매핑 누락 ru_param 집계 SQLite 경로 (비우면 사용 안 함)
 	} `yaml:"file_dir"`
 	Logging struct {
 		LogPrefix        string `yaml:"log_prefix"`        // 로그 파일 접두사 (예: "fetch_xml_files")
@@ -27,7 +33,82 @@ type Config struct {
 	} `yaml:"logging"`
 	Worker struct {
 		OpenFileWorkerCount int `yaml:"open_file_worker_count"`
+		MaxRetries          int `yaml:"max_retries"`          // 파일 열기 실패 시 최대 재시도 횟수
+		RetryDelaySec       int `yaml:"retry_delay_sec"`      // 재시도 간격 (초)
+		ShutdownTimeoutSec  int `yaml:"shutdown_timeout_sec"` // 종료 시 처리 중 파일 대기 + 인덱서 flush 제한 시간 (초, 기본 30, 마지막 flush 는 최소 10초 별도)
 	} `yaml:"worker"`
+	Parser struct {
+		Vendor    string `yaml:"vendor"`     // PM 파일 벤더 (SAMSUNG, ERICSSON, NOKIA — ERICSSON/NOKIA 는 LTE 만), 비우면 SAMSUNG
+		RulesFile string `yaml:"rules_file"` // measInfo 매핑 규칙 파일 (비우면 벤더/세대별 내장 기본 규칙)
+	} `yaml:"parser"`
+	Sink struct {
+		Outputs []string `yaml:"outputs"` // 출력 대상: elasticsearch, file, kafka (여러 개 지정 가능, 비우면 elasticsearch)
+		File    struct {
+			Dir           string `yaml:"dir"`            // NDJSON 파일 저장 경로
+			Prefix        string `yaml:"prefix"`         // 파일 이름 접두사 (기본 kpi)
+			MaxSizeMB     int    `yaml:"max_size_mb"`    // 파일 최대 크기 (압축 전, 0 이면 크기 회전 안 함)
+			RotateMinutes int    `yaml:"rotate_minutes"` // 파일 회전 주기 (분, 0 이면 시간 회전 안 함)
+			Gzip          bool   `yaml:"gzip"`           // gzip 압축 여부
+		} `yaml:"file"`
+		Kafka struct {
+			Brokers         []string `yaml:"brokers"`           // 브로커 주소 목록 (host:port)
+			Topic           string   `yaml:"topic"`             // 토픽 템플릿, {montype} {generation} {equip_id} 치환
+			Key             string   `yaml:"key"`               // 파티션 키: ru_param | cell_id | none (기본 ru_param)
+			BatchSize       int      `yaml:"batch_size"`        // 배치 최대 메시지 수
+			BatchBytes      int64    `yaml:"batch_bytes"`       // 배치 최대 크기
+			LingerMs        int      `yaml:"linger_ms"`         // 배치가 덜 차도 보내는 최대 대기 시간 (ms)
+			Compression     string   `yaml:"compression"`       // none | gzip | snappy | lz4 | zstd
+			RequiredAcks    string   `yaml:"required_acks"`     // none | one | all (기본 all)
+			MaxAttempts     int      `yaml:"max_attempts"`      // 전송 재시도 횟수
+			AutoCreateTopic bool     `yaml:"auto_create_topic"` // 토픽이 없으면 생성 요청
+		} `yaml:"kafka"`
+	} `yaml:"sink"`
+	Spool struct {
+		Dir       string `yaml:"dir"`        // 파서와 벌크 인덱서 사이 디스크 스풀 경로 (비우면 메모리 채널만 사용)
+		SegmentMB int    `yaml:"segment_mb"` // 세그먼트 파일 최대 크기 (기본 64)
+	} `yaml:"spool"`
+	DLQ struct {
+		Dir         string `yaml:"dir"`           // 색인 실패 항목 저장 경로 (비우면 사용 안 함)
+		MaxSizeMB   int    `yaml:"max_size_mb"`   // 디렉터리 최대 크기, 넘으면 오래된 파일부터 삭제 (0 이면 제한 없음)
+		MaxAgeHours int    `yaml:"max_age_hours"` // 파일 보존 기간 (0 이면 제한 없음)
+	} `yaml:"dlq"`
+	Backfill struct {
+		Enabled     bool   `yaml:"enabled"`       // 시작 시 scan_dir 에 남아 있는 파일 처리 여부
+		MaxAgeHours int    `yaml:"max_age_hours"` // 이보다 오래된 파일(mtime)은 제외, 0 이면 제한 없음
+		Order       string `yaml:"order"`         // 처리 순서: endtime(기본) | mtime
+		SkipIndexed bool   `yaml:"skip_indexed"`  // 파일이 만들 문서가 ES 에 모두 있으면 건너뜀 (elasticsearch 출력일 때만)
+	} `yaml:"backfill"`
+	Mapping struct {
+		// ru_mapping 공급원. 뒤에 있는 공급원이 같은 ru_param 을 덮어씀 (비우면 file_dir.sqlite_dir 의 SQLite 하나)
+		Sources []struct {
+			Type       string            `yaml:"type"`        // sqlite | csv | json | http
+			Path       string            `yaml:"path"`        // sqlite/csv/json 파일 경로 (sqlite 는 비우면 file_dir.sqlite_dir)
+			URL        string            `yaml:"url"`         // http 엔드포인트 (JSON 응답)
+			Headers    map[string]string `yaml:"headers"`     // http 요청 헤더 (예: Authorization)
+			TimeoutSec int               `yaml:"timeout_sec"` // http 요청 제한 시간 (초, 기본 30)
+			Optional   bool              `yaml:"optional"`    // 실패해도 적재 계속 (마지막으로 읽은 결과 사용)
+		} `yaml:"sources"`
+		// ru_mapping 추가 컬럼 → 문서 attrs / location(geo_point)
+		Attributes struct {
+			Columns   []string `yaml:"columns"`   // 문서 attrs 로 복사할 추가 컬럼 (예: site_name, band, region)
+			Latitude  string   `yaml:"latitude"`  // 위도 컬럼 (경도와 함께 지정하면 문서 location 생성)
+			Longitude string   `yaml:"longitude"` // 경도 컬럼
+		} `yaml:"attributes"`
+		ReloadIntervalMin *int   `yaml:"reload_interval_min"` // ru_mapping 주기 재적재 간격 (분, 생략하면 360, 0 이하면 주기 재적재 안 함)
+		Watch             bool   `yaml:"watch"`               // 공급원 파일(SQLite 는 WAL 포함) 변경 감지 시 재적재
+		DebounceSec       int    `yaml:"debounce_sec"`        // 파일 변경이 멈춘 뒤 재적재까지 대기 (초, 기본 2)
+		SIGHUP            bool   `yaml:"sighup"`              // SIGHUP 수신 시 재적재
+		HistoryDB         string `yaml:"history_db"`          // 재적재 때 바뀐 매핑의 이전 버전 기록 SQLite 경로 (비우면 메모리에만 보관)
+		HistoryDays       int    `yaml:"history_days"`        // 지난 버전 보존 기간 (일, 0 이면 제한 없음)
+	} `yaml:"mapping"`
+	HTTP struct {
+		Listen     string `yaml:"listen"`      // 메트릭(/metrics)/헬스(/healthz, /readyz)/관리 API HTTP 서버 주소 (예: ":9108", 비우면 사용 안 함)
+		AdminToken string `yaml:"admin_token"` // 관리 API(/admin/) Bearer 토큰 (비우면 관리 API 비활성화)
+	} `yaml:"http"`
+	Health struct {
+		MappingMaxAgeHours int `yaml:"mapping_max_age_hours"` // ru_mapping 적재 후 이 시간이 지나면 준비 실패 (0 이면 적재 여부만 확인)
+		StalePeriods       int `yaml:"stale_periods"`         // 수집 주기 × 이 값(분) 동안 입력 파일이 없으면 준비 실패 (0 이면 검사 안 함)
+	} `yaml:"health"`
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -44,3 +125,57 @@ func LoadConfig(configPath string) (*Config, error) {
 	}
 	return &cfg, nil
 }
+
+// Vendor: parser.vendor 를 대문자로 정규화, 비어 있으면 SAMSUNG
+func (c *Config) Vendor() string {
+	v := strings.ToUpper(strings.TrimSpace(c.Parser.Vendor))
+	if v == "" {
+		return "SAMSUNG"
+	}
+	return v
+}
+
+// Generation: elasticsearch.generation 정규화 (4G/LTE → LTE, 5G/NR → NR), 비어 있으면 LTE
+func (c *Config) Generation() string {
+	g := strings.ToUpper(strings.TrimSpace(c.Elasticsearch.Generation))
+	switch g {
+	case "", "4G", "LTE":
+		return "LTE"
+	case "5G", "NR":
+		return "NR"
+	}
+	return g
+}
+
+// DefaultReloadIntervalMin: mapping.reload_interval_min 을 생략했을 때의 주기 재적재 간격 (기존 6시간 갱신 주기)
+const DefaultReloadIntervalMin = 360
+
+// ReloadInterval: ru_mapping 주기 재적재 간격. 생략하면 DefaultReloadIntervalMin, 0 이하로 지정하면 0 (재적재 안 함).
+func (c *Config) ReloadInterval() time.Duration {
+	minutes := DefaultReloadIntervalMin
+	if c.Mapping.ReloadIntervalMin != nil {
+		minutes = *c.Mapping.ReloadIntervalMin
+	}
+	if minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// Outputs: sink.outputs 를 소문자로 정규화 (중복 제거), 비어 있으면 elasticsearch
+func (c *Config) Outputs() []string {
+	var outs []string
+	seen := make(map[string]bool)
+	for _, o := range c.Sink.Outputs {
+		o = strings.ToLower(strings.TrimSpace(o))
+		if o == "" || seen[o] {
+			continue
+		}
+		seen[o] = true
+		outs = append(outs, o)
+	}
+	if len(outs) == 0 {
+		return []string{"elasticsearch"}
+	}
+	return outs
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+)
+
+// TestReloadInterval: reload_interval_min 을 생략한 기존 설정은 6시간 주기 재적재를 유지하고, 0 이하로 지정해야 꺼짐
+func TestReloadInterval(t *testing.T) {
+	cases := []struct {
+		yaml string
+		want time.Duration
+	}{
+		{"mapping: {}", 6 * time.Hour},
+		{"mapping:\n  reload_interval_min: 30", 30 * time.Minute},
+		{"mapping:\n  reload_interval_min: 0", 0},
+		{"mapping:\n  reload_interval_min: -1", 0},
+	}
+	for _, tc := range cases {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tc.yaml), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.ReloadInterval(); got != tc.want {
+			t.Errorf("%q: ReloadInterval = %s, want %s", tc.yaml, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package dlq
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filePrefix = "dlq-"
+	fileSuffix = ".ndjson"
+	fileLayout = "2006010215" // 시간 단위 파일
+)
+
+// Record: 색인에 실패한 벌크 항목 한 건 (NDJSON 한 줄)
+type Record struct {
+	Index      string          `json:"index"`
+	DocumentID string          `json:"document_id"`
+	Body       json.RawMessage `json:"body"`
+	Reason     string          `json:"reason"`
+	SourceFile string          `json:"source_file,omitempty"`
+	FailedAt   time.Time       `json:"failed_at"`
+}
+
+// Queue: 디스크 기반 dead-letter 큐. 시간별 NDJSON 파일(dlq-YYYYMMDDHH.ndjson)에 추가 기록.
+// 디렉터리 총 크기(maxBytes)와 파일 보존 기간(maxAge)을 넘으면 오래된 파일부터 삭제 (재전송 중인 .replay 파일 포함).
+type Queue struct {
+	dir      string
+	maxBytes int64         // 0 이면 제한 없음
+	maxAge   time.Duration // 0 이면 제한 없음
+
+	mu      sync.Mutex
+	name    string // 현재 기록 중인 파일 이름
+	f       *os.File
+	w       *bufio.Writer
+	written int64 // 마지막 용량 정리 이후 기록한 바이트
+}
+
+// Open: dir 에 큐 생성 (없으면 디렉터리 생성) 후 용량/기간 정리
+func Open(dir string, maxSizeMB, maxAgeHours int) (*Queue, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("dlq dir: %w", err)
+	}
+	q := &Queue{
+		dir:      dir,
+		maxBytes: int64(maxSizeMB) << 20,
+		maxAge:   time.Duration(maxAgeHours) * time.Hour,
+	}
+	if _, err := q.Prune(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Dir: 큐 디렉터리
+func (q *Queue) Dir() string {
+	return q.dir
+}
+
+// Write: 실패 항목 기록. 파일 경계(시간)가 바뀌면 새 파일로 전환.
+func (q *Queue) Write(rec Record) error {
+	if rec.FailedAt.IsZero() {
+		rec.FailedAt = time.Now()
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal dlq record: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	name := FileName(rec.FailedAt)
+	if q.f == nil || name != q.name {
+		if err := q.rotate(name); err != nil {
+			return err
+		}
+	}
+	if _, err := q.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write dlq: %w", err)
+	}
+	// 프로세스가 죽어도 기록이 남도록 건마다 flush
+	if err := q.w.Flush(); err != nil {
+		return fmt.Errorf("flush dlq: %w", err)
+	}
+
+	// 용량 제한의 1/10 을 쓸 때마다 정리
+	q.written += int64(len(line)) + 1
+	if q.maxBytes > 0 && q.written >= q.maxBytes/10 {
+		q.written = 0
+		if _, err := q.prune(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close: 현재 파일 flush 후 닫기
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closeFile()
+}
+
+// Prune: 보존 기간이 지났거나 총 크기를 넘는 오래된 파일 삭제. 삭제한 파일 수 반환.
+func (q *Queue) Prune() (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.prune()
+}
+
+func (q *Queue) rotate(name string) error {
+	if err := q.closeFile(); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(q.dir, name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open dlq file: %w", err)
+	}
+	q.name, q.f, q.w = name, f, bufio.NewWriter(f)
+	return nil
+}
+
+func (q *Queue) closeFile() error {
+	if q.f == nil {
+		return nil
+	}
+	flushErr := q.w.Flush()
+	closeErr := q.f.Close()
+	q.f, q.w, q.name = nil, nil, ""
+	if flushErr != nil {
+		return fmt.Errorf("flush dlq: %w", flushErr)
+	}
+	return closeErr
+}
+
+func (q *Queue) prune() (int, error) {
+	files, err := Files(q.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	sizes := make([]int64, len(files))
+	for i, path := range files {
+		if fi, err := os.Stat(path); err == nil {
+			sizes[i] = fi.Size()
+			total += fi.Size()
+		}
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-q.maxAge)
+	for i, path := range files {
+		name := filepath.Base(path)
+		if name == q.name {
+			continue // 기록 중인 파일은 남김
+		}
+		// 시간별 파일이므로 파일 시각 + 1시간이 마지막 기록 시각
+		expired := q.maxAge > 0 && fileTime(name).Add(time.Hour).Before(cutoff)
+		oversize := q.maxBytes > 0 && total > q.maxBytes
+		if !expired && !oversize {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("remove dlq file: %w", err)
+		}
+		total -= sizes[i]
+		removed++
+	}
+	return removed, nil
+}
+
+// FileName: t 시각 기록이 들어갈 파일 이름
+func FileName(t time.Time) string {
+	return filePrefix + t.Format(fileLayout) + fileSuffix
+}
+
+// Files: dir 의 DLQ 파일 경로 (오래된 순). 재전송 중이거나 중단된 .replay 파일 포함.
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read dlq dir: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), replaySuffix)
+		if !e.IsDir() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// fileTime: 파일 이름의 시각 (형식이 다르면 zero)
+func fileTime(name string) time.Time {
+	ts := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSuffix(name, replaySuffix), filePrefix), fileSuffix)
+	t, _ := time.ParseInLocation(fileLayout, ts, time.Local)
+	return t
+}
@@ -0,0 +1,108 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPruneReplayFiles: 중단된 재전송이 남긴 .replay 파일도 보존 기간/용량 정리 대상
+func TestPruneReplayFiles(t *testing.T) {
+	dir := t.TempDir()
+	old := FileName(time.Now().Add(-48 * time.Hour))
+	recent := FileName(time.Now().Add(-time.Hour))
+	writeLines(t, filepath.Join(dir, old+replaySuffix), `{"index":"kpi","body":{}}`)
+	writeLines(t, filepath.Join(dir, old), `{"index":"kpi","body":{}}`)
+	writeLines(t, filepath.Join(dir, recent+replaySuffix), `{"index":"kpi","body":{}}`)
+
+	q, err := Open(dir, 0, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	files, err := Files(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != recent+replaySuffix {
+		t.Errorf("files after prune = %v", files)
+	}
+}
+
+// fakeIndexer: Add 즉시 본문에 "fail" 이 있으면 OnFailure, 아니면 성공으로 처리
+type fakeIndexer struct {
+	added int
+}
+
+func (f *fakeIndexer) Add(ctx context.Context, item esutil.BulkIndexerItem) error {
+	f.added++
+	b, _ := io.ReadAll(item.Body)
+	if strings.Contains(string(b), "fail") {
+		item.OnFailure(ctx, item, esutil.BulkIndexerResponseItem{}, errors.New("flush: connection refused"))
+	}
+	return nil
+}
+
+func (f *fakeIndexer) Close(context.Context) error    { return nil }
+func (f *fakeIndexer) Stats() esutil.BulkIndexerStats { return esutil.BulkIndexerStats{} }
+
+func TestReplay(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	dir := t.TempDir()
+	name := FileName(time.Now().Add(-2 * time.Hour))
+	writeLines(t, filepath.Join(dir, name),
+		`{"index":"kpi-2024.05.01","document_id":"a","body":{"v":1}}`,
+		`not json`,
+		`{"index":"kpi-2024.05.01","document_id":"b","body":{"v":"fail"}}`,
+		`{"index":"kpi-2024.05.01","document_id":"c","body":{"v":3}}`,
+	)
+	q, err := Open(dir, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	ix := &fakeIndexer{}
+	stats, err := Replay(context.Background(), logger, ix, q, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ReplayStats{Files: 1, Records: 3, Succeeded: 2, Failed: 1, Malformed: 1}
+	if stats != want || ix.added != 3 {
+		t.Errorf("stats = %+v added = %d, want %+v", stats, ix.added, want)
+	}
+
+	// 원본은 삭제, 재실패 항목은 현재 시간 파일에 새 사유로 재기록
+	q.Close()
+	files, err := Files(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != FileName(time.Now()) {
+		t.Fatalf("files = %v", files)
+	}
+	b, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(b), "\n") != 1 || !strings.Contains(string(b), `"document_id":"b"`) || !strings.Contains(string(b), "connection refused") {
+		t.Errorf("requeued = %s", b)
+	}
+}
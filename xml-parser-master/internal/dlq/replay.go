@@ -0,0 +1,165 @@
+package dlq
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// replaySuffix: 재전송 중인 파일에 붙이는 접미사 (중단되면 다음 재전송에서 이어서 처리)
+const replaySuffix = ".replay"
+
+// ReplayStats: 재전송 결과
+type ReplayStats struct {
+	Files     int `json:"files"`
+	Records   int `json:"records"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`    // 다시 실패하여 큐에 재기록
+	Malformed int `json:"malformed"` // 읽을 수 없는 줄 (버림)
+}
+
+// Replay: q 의 DLQ 파일을 오래된 순으로 한 줄씩 읽어 indexer 로 재전송하고 indexer 를 닫음.
+// all 이 false 면 서비스가 기록 중일 수 있는 현재 시간 파일은 제외.
+// 대상 파일은 먼저 .replay 로 이름을 바꾼 뒤 읽고, 전송이 끝나면 삭제하며,
+// 다시 실패한 항목은 새 실패 사유와 함께 바로 q 에 재기록 (현재 시간 파일이므로 이번 재전송 대상이 아님).
+func Replay(ctx context.Context, logger *logrus.Logger, indexer esutil.BulkIndexer, q *Queue, all bool) (ReplayStats, error) {
+	var stats ReplayStats
+
+	files, err := claim(q.dir, all)
+	if err != nil {
+		return stats, err
+	}
+	stats.Files = len(files)
+
+	var mu sync.Mutex
+	var writeErr error // 재실패 항목 재기록 중 처음 난 오류
+	requeue := func(rec Record, reason string) {
+		rec.Reason = reason
+		rec.FailedAt = time.Now()
+		err := q.Write(rec)
+		mu.Lock()
+		stats.Failed++
+		if err != nil && writeErr == nil {
+			writeErr = err
+		}
+		mu.Unlock()
+	}
+
+	for _, path := range files {
+		n, malformed, err := readFile(path, func(rec Record) {
+			err := indexer.Add(ctx, esutil.BulkIndexerItem{
+				Action:     "index",
+				Index:      rec.Index,
+				DocumentID: rec.DocumentID,
+				Body:       bytes.NewReader(rec.Body),
+				OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+					requeue(rec, failureReason(res, err))
+				},
+			})
+			if err != nil {
+				requeue(rec, err.Error())
+			}
+		})
+		stats.Records += n
+		stats.Malformed += malformed
+		if err != nil {
+			indexer.Close(ctx)
+			return stats, err
+		}
+		logger.Infof("DLQ 재전송: %s (%d건)", filepath.Base(path), n)
+	}
+
+	if err := indexer.Close(ctx); err != nil {
+		return stats, fmt.Errorf("close indexer: %w", err)
+	}
+	stats.Succeeded = stats.Records - stats.Failed
+	if writeErr != nil {
+		// 재실패 항목을 다 남기지 못했으므로 원본 .replay 파일을 지우지 않음 (다음 재전송에서 다시 처리)
+		return stats, writeErr
+	}
+
+	for _, path := range files {
+		// 재전송 중 서비스의 용량/기간 정리로 먼저 삭제되었을 수 있음
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return stats, fmt.Errorf("remove replayed file: %w", err)
+		}
+	}
+	return stats, nil
+}
+
+// claim: 재전송 대상 파일을 .replay 로 이름 변경 (이전에 중단된 .replay 파일 포함)
+func claim(dir string, all bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read dlq dir: %w", err)
+	}
+	current := FileName(time.Now())
+
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, filePrefix) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		switch {
+		case strings.HasSuffix(name, fileSuffix+replaySuffix):
+			files = append(files, path)
+		case strings.HasSuffix(name, fileSuffix):
+			if !all && name == current {
+				continue
+			}
+			if err := os.Rename(path, path+replaySuffix); err != nil {
+				return nil, fmt.Errorf("claim dlq file: %w", err)
+			}
+			files = append(files, path+replaySuffix)
+		}
+	}
+	return files, nil
+}
+
+// readFile: NDJSON 파일을 한 줄씩 읽어 레코드마다 fn 호출. 넘긴 레코드 수와 깨진 줄 수 반환.
+func readFile(path string, fn func(Record)) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("open dlq file: %w", err)
+	}
+	defer f.Close()
+
+	n, malformed := 0, 0
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil || rec.Index == "" || len(rec.Body) == 0 {
+			malformed++
+			continue
+		}
+		fn(rec)
+		n++
+	}
+	if err := sc.Err(); err != nil {
+		return n, malformed, fmt.Errorf("read dlq file %s: %w", path, err)
+	}
+	return n, malformed, nil
+}
+
+func failureReason(res esutil.BulkIndexerResponseItem, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return res.Error.Type + ": " + res.Error.Reason
+}
@@ -0,0 +1,321 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+	"github.com/sirupsen/logrus"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ esutil.BulkIndexer = (*bulkIndexer)(nil)
+
+var (
+	// errNoResponse: flush 는 끝났지만 항목 응답이 오지 않음 (응답 items 누락)
+	errNoResponse = errors.New("bulk response missing item")
+	// errClosed: Close 이후의 Add (종료 시간 초과 후 출력 워커가 아직 문서를 넘기는 경우)
+	errClosed = errors.New("bulk indexer closed")
+)
+
+// bulkIndexer: 벌크 요청 자체가 실패해도(ES 중단, 연결 끊김, 4xx/5xx 응답 등) 항목마다 OnFailure 가 호출되도록 감싼 인덱서.
+// esutil 은 요청 단위 실패 시 OnError 만 호출하고 그 요청의 항목은 버리므로,
+// 워커 하나짜리 esutil 인덱서(shard)를 workers 개 두고 shard 별로 버퍼에 들어간 항목을 기록했다가
+// flush 가 끝날 때까지 응답을 받지 못한 항목에 OnFailure 를 호출.
+//
+// esutil(go-elasticsearch v7.17.10) 내부 동작에 기대는 부분 (문서화된 API 가 아님):
+//   - 워커는 항목을 받으면 본문을 버퍼에 쓰면서 읽고, 본문 읽기와 flush 를 같은 워커 잠금 안에서 함
+//     → 본문을 읽은 뒤 처음 시작되는 flush 에 그 항목이 실림 (bufferedBody.onRead → shard.flushStart)
+//   - OnFlushStart 가 돌려준 ctx 를 같은 flush 의 OnError, OnFlushEnd 에 넘기고,
+//     워커는 flush 밖에서 같은 "flush: " 오류로 OnError 를 한 번 더 호출
+//
+// go-elasticsearch 를 올릴 때는 TestEsutilFlushContract, TestEsutilFlushErrorContext 로 확인.
+type bulkIndexer struct {
+	logger *logrus.Logger
+	queue  chan esutil.BulkIndexerItem // shard 들이 나눠 가져감 (먼저 비는 shard 가 받음)
+	shards []*shard
+	wg     sync.WaitGroup
+	ctx    context.Context // Close 제한 시간이 지나면 취소 (shard 로 넘기지 못한 항목 실패 처리)
+	cancel context.CancelFunc
+	failed uint64 // shard 에 넣지 못했거나 Close 까지 응답이 없어 실패 처리한 항목 수
+
+	closing chan struct{} // Close 가 시작되면 닫힘 (블록된 Add 를 깨움)
+	addMu   sync.RWMutex  // Add 진행 중에는 queue 를 닫지 않음
+	closed  bool
+
+	mu      sync.Mutex
+	pending map[*entry]struct{} // 응답 대기 중인 항목
+}
+
+// shard: 워커 하나짜리 esutil 인덱서. 본문 읽기와 flush 가 같은 워커 잠금 안에서 순서대로 일어나므로
+// 본문을 읽은 순서가 곧 다음 flush 에 실리는 항목 순서.
+type shard struct {
+	bi esutil.BulkIndexer
+
+	mu       sync.Mutex
+	buffered []*entry // 버퍼에 들어가 다음 flush 를 기다리는 항목
+}
+
+// entry: 원래 항목과 응답 여부. OnSuccess/OnFailure 는 settle 에 성공한 쪽만 한 번 호출.
+type entry struct {
+	item    esutil.BulkIndexerItem
+	settled int32
+}
+
+type flushKey struct{}
+
+// flushState: flush 한 번에 실린 항목과 요청 실패 사유 (OnFlushStart 가 ctx 에 담음)
+type flushState struct {
+	entries []*entry
+	err     error
+}
+
+// newBulkIndexer: workers 개의 shard 로 인덱서 생성. index 는 항목에 인덱스가 없을 때의 기본값.
+func newBulkIndexer(logger *logrus.Logger, esClient *elasticsearch.Client, index string, workers, flushBytes int, flushInterval time.Duration) (*bulkIndexer, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ix := &bulkIndexer{
+		logger:  logger,
+		queue:   make(chan esutil.BulkIndexerItem, workers),
+		ctx:     ctx,
+		cancel:  cancel,
+		closing: make(chan struct{}),
+		pending: make(map[*entry]struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		s := &shard{}
+		bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+			Client:        esClient,
+			Index:         index,
+			NumWorkers:    1,
+			FlushBytes:    flushBytes,
+			FlushInterval: flushInterval,
+			OnError:       ix.onError,
+			OnFlushStart:  s.flushStart,
+			OnFlushEnd:    ix.flushEnd,
+		})
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		s.bi = bi
+		ix.shards = append(ix.shards, s)
+	}
+	ix.wg.Add(workers)
+	for _, s := range ix.shards {
+		go ix.forward(s)
+	}
+	return ix, nil
+}
+
+// Add: 항목을 큐에 넣음. 모든 shard 가 flush 중이면 ctx 가 끝나거나 Close 가 시작될 때까지 블록.
+// esutil 과 달리 Close 이후에 호출해도 panic 하지 않고 errClosed 반환.
+func (ix *bulkIndexer) Add(ctx context.Context, item esutil.BulkIndexerItem) error {
+	ix.addMu.RLock()
+	defer ix.addMu.RUnlock()
+	if ix.closed {
+		return errClosed
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-ix.closing:
+		return errClosed
+	case ix.queue <- item:
+		return nil
+	}
+}
+
+// forward: 큐의 항목을 감싸 shard 에 넣음
+func (ix *bulkIndexer) forward(s *shard) {
+	defer ix.wg.Done()
+	for item := range ix.queue {
+		e := &entry{item: item}
+		ix.mu.Lock()
+		ix.pending[e] = struct{}{}
+		ix.mu.Unlock()
+
+		if err := s.bi.Add(ix.ctx, ix.wrap(s, e)); err != nil && ix.fail(ix.ctx, e, err) {
+			atomic.AddUint64(&ix.failed, 1)
+		}
+	}
+}
+
+// wrap: 본문을 처음 읽을 때 shard 버퍼에 기록하고, 응답 콜백은 settle 을 거쳐 원래 콜백 호출
+func (ix *bulkIndexer) wrap(s *shard, e *entry) esutil.BulkIndexerItem {
+	item := e.item
+	if item.Body != nil {
+		item.Body = &bufferedBody{r: item.Body, onRead: func() { s.buffer(e) }}
+	}
+	item.OnSuccess = func(ctx context.Context, it esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
+		if ix.settle(e) && e.item.OnSuccess != nil {
+			it.OnSuccess, it.OnFailure = e.item.OnSuccess, e.item.OnFailure
+			e.item.OnSuccess(ctx, it, res)
+		}
+	}
+	item.OnFailure = func(ctx context.Context, it esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+		if ix.settle(e) && e.item.OnFailure != nil {
+			it.OnSuccess, it.OnFailure = e.item.OnSuccess, e.item.OnFailure
+			e.item.OnFailure(ctx, it, res, err)
+		}
+	}
+	return item
+}
+
+// settle: 항목 응답 처리 권한 획득 (처음 한 번만 true)
+func (ix *bulkIndexer) settle(e *entry) bool {
+	if !atomic.CompareAndSwapInt32(&e.settled, 0, 1) {
+		return false
+	}
+	ix.mu.Lock()
+	delete(ix.pending, e)
+	ix.mu.Unlock()
+	return true
+}
+
+// fail: 응답을 받지 못한 항목 실패 처리 (이미 응답된 항목이면 false)
+func (ix *bulkIndexer) fail(ctx context.Context, e *entry, err error) bool {
+	if !ix.settle(e) {
+		return false
+	}
+	if e.item.OnFailure != nil {
+		e.item.OnFailure(ctx, e.item, esutil.BulkIndexerResponseItem{}, err)
+	}
+	return true
+}
+
+// buffer: 항목이 shard 버퍼에 들어감
+func (s *shard) buffer(e *entry) {
+	s.mu.Lock()
+	s.buffered = append(s.buffered, e)
+	s.mu.Unlock()
+}
+
+// flushStart: 지금까지 버퍼에 들어간 항목을 이번 flush 몫으로 ctx 에 담음
+func (s *shard) flushStart(ctx context.Context) context.Context {
+	s.mu.Lock()
+	st := &flushState{entries: s.buffered}
+	s.buffered = nil
+	s.mu.Unlock()
+	return context.WithValue(ctx, flushKey{}, st)
+}
+
+// onError: flush 안에서 난 요청 실패는 flushEnd 에서 항목과 함께 기록하고, 그 밖의 인덱서 오류는 로그만 남김
+func (ix *bulkIndexer) onError(ctx context.Context, err error) {
+	if st, ok := ctx.Value(flushKey{}).(*flushState); ok {
+		st.err = err
+		return
+	}
+	// esutil 은 flush 실패를 flush 밖에서 한 번 더 알리므로 중복 로그 생략.
+	// Close 제한 시간 초과로 취소된 Add 는 항목별 OnFailure 로 이미 기록됨.
+	if strings.HasPrefix(err.Error(), "flush: ") || errors.Is(err, context.Canceled) {
+		return
+	}
+	ix.logger.Errorf("벌크 인덱서 오류: %v", err)
+}
+
+// flushEnd: 이번 flush 에 실렸지만 응답이 없는 항목(요청 실패, 응답 누락)을 실패 처리
+func (ix *bulkIndexer) flushEnd(ctx context.Context) {
+	st, ok := ctx.Value(flushKey{}).(*flushState)
+	if !ok {
+		return
+	}
+	reason := st.err
+	if reason == nil {
+		reason = errNoResponse
+	}
+	n := 0
+	for _, e := range st.entries {
+		if ix.fail(ctx, e, reason) {
+			n++
+		}
+	}
+	if n > 0 {
+		ix.logger.Errorf("벌크 요청 실패: %d건 실패 처리: %v", n, reason)
+	}
+}
+
+// Close: 큐를 비우고 shard 별로 남은 버퍼 flush 후 종료.
+// ctx 가 먼저 끝나 flush 하지 못한 항목도 OnFailure 로 실패 처리.
+func (ix *bulkIndexer) Close(ctx context.Context) error {
+	close(ix.closing)
+	ix.addMu.Lock()
+	ix.closed = true
+	ix.addMu.Unlock()
+	close(ix.queue)
+	done := make(chan struct{})
+	go func() {
+		ix.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		ix.cancel() // 남은 큐 항목은 shard.Add 실패로 바로 실패 처리
+		<-done
+	}
+	defer ix.cancel()
+
+	var errs []error
+	for _, s := range ix.shards {
+		if err := s.bi.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	ix.mu.Lock()
+	left := make([]*entry, 0, len(ix.pending))
+	for e := range ix.pending {
+		left = append(left, e)
+	}
+	ix.mu.Unlock()
+	reason := ctx.Err()
+	if reason == nil {
+		reason = errNoResponse
+	}
+	n := 0
+	for _, e := range left {
+		if ix.fail(ctx, e, fmt.Errorf("indexer closed before flush: %w", reason)) {
+			n++
+		}
+	}
+	if n > 0 {
+		atomic.AddUint64(&ix.failed, uint64(n))
+		ix.logger.Errorf("벌크 인덱서 종료: flush 하지 못한 %d건 실패 처리", n)
+	}
+	return errors.Join(errs...)
+}
+
+// Stats: shard 통계 합계
+func (ix *bulkIndexer) Stats() esutil.BulkIndexerStats {
+	var sum esutil.BulkIndexerStats
+	for _, s := range ix.shards {
+		st := s.bi.Stats()
+		sum.NumAdded += st.NumAdded
+		sum.NumFlushed += st.NumFlushed
+		sum.NumFailed += st.NumFailed
+		sum.NumIndexed += st.NumIndexed
+		sum.NumCreated += st.NumCreated
+		sum.NumUpdated += st.NumUpdated
+		sum.NumDeleted += st.NumDeleted
+		sum.NumRequests += st.NumRequests
+	}
+	sum.NumFailed += atomic.LoadUint64(&ix.failed)
+	return sum
+}
+
+// bufferedBody: 처음 읽힐 때(워커가 항목을 버퍼에 쓸 때) onRead 호출
+type bufferedBody struct {
+	r      io.Reader
+	once   sync.Once
+	onRead func()
+}
+
+func (b *bufferedBody) Read(p []byte) (int, error) {
+	b.once.Do(b.onRead)
+	return b.r.Read(p)
+}
@@ -0,0 +1,168 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type groupKey struct{}
+
+// TestEsutilFlushContract: bulkIndexer 가 기대는 esutil(v7.17.10) 단일 워커 동작을 실제 esutil 로 확인.
+// go-elasticsearch 를 올릴 때 이 테스트가 깨지면 bulkIndexer 의 항목-flush 대응이 틀어진 것.
+//   - 워커는 항목 본문을 버퍼에 쓸 때 읽고(bufferedBody.onRead), 본문 읽기와 flush 는 같은 워커 잠금 안에서 일어남
+//     → OnFlushStart 전에 본문을 읽은 항목이 정확히 그 flush 요청에 실림 (flush 중에는 본문을 읽지 않음)
+//   - OnFlushStart 가 돌려준 ctx 가 같은 flush 의 OnError, OnFlushEnd 에 그대로 전달됨
+func TestEsutilFlushContract(t *testing.T) {
+	cases := []struct {
+		name          string
+		items         int
+		flushBytes    int
+		flushInterval time.Duration
+	}{
+		{"flush per item", 5, 1, time.Hour},
+		{"flush on close", 5, 5 << 20, time.Hour},
+		{"size and ticker flushes", 300, 200, time.Millisecond},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var requests, flushed [][]string
+			var pending []string
+			srv := fakeBulkServerFunc(t, func(ids []string) {
+				mu.Lock()
+				requests = append(requests, ids)
+				mu.Unlock()
+			})
+			defer srv.Close()
+			client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}, DisableRetry: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+				Client:        client,
+				Index:         "kpi",
+				NumWorkers:    1,
+				FlushBytes:    tc.flushBytes,
+				FlushInterval: tc.flushInterval,
+				OnFlushStart: func(ctx context.Context) context.Context {
+					mu.Lock()
+					group := pending
+					pending = nil
+					mu.Unlock()
+					return context.WithValue(ctx, groupKey{}, group)
+				},
+				OnFlushEnd: func(ctx context.Context) {
+					group, _ := ctx.Value(groupKey{}).([]string)
+					if len(group) == 0 {
+						return
+					}
+					mu.Lock()
+					flushed = append(flushed, group)
+					mu.Unlock()
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tc.items; i++ {
+				id := strconv.Itoa(i)
+				err := bi.Add(context.Background(), esutil.BulkIndexerItem{
+					Action:     "index",
+					DocumentID: id,
+					Body: &bufferedBody{r: strings.NewReader(fmt.Sprintf(`{"v":%d}`, i)), onRead: func() {
+						mu.Lock()
+						pending = append(pending, id)
+						mu.Unlock()
+					}},
+					OnSuccess: func(context.Context, esutil.BulkIndexerItem, esutil.BulkIndexerResponseItem) {},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := bi.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(pending) != 0 {
+				t.Errorf("items read but never flushed: %v", pending)
+			}
+			if !reflect.DeepEqual(flushed, requests) {
+				t.Errorf("flush groups differ from bulk requests\nflushed  %v\nrequests %v", flushed, requests)
+			}
+			n := 0
+			for _, g := range requests {
+				n += len(g)
+			}
+			if n != tc.items {
+				t.Errorf("requested %d items, want %d", n, tc.items)
+			}
+		})
+	}
+}
+
+// TestEsutilFlushErrorContext: 요청 실패 시 OnError 와 OnFlushEnd 가 OnFlushStart 의 ctx 를 받아야 함
+func TestEsutilFlushErrorContext(t *testing.T) {
+	srv := fakeBulkServer(t)
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}, DisableRetry: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	var mu sync.Mutex
+	var onError, onEnd []bool
+	var errs []string
+	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client:     client,
+		NumWorkers: 1,
+		FlushBytes: 1,
+		OnFlushStart: func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, groupKey{}, []string{"x"})
+		},
+		OnError: func(ctx context.Context, err error) {
+			mu.Lock()
+			onError = append(onError, ctx.Value(groupKey{}) != nil)
+			errs = append(errs, err.Error())
+			mu.Unlock()
+		},
+		OnFlushEnd: func(ctx context.Context) {
+			mu.Lock()
+			onEnd = append(onEnd, ctx.Value(groupKey{}) != nil)
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bi.Add(context.Background(), esutil.BulkIndexerItem{Action: "index", Index: "kpi", Body: strings.NewReader(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+	bi.Close(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	// flush 안의 OnError(ctx 있음)와 워커가 flush 밖에서 한 번 더 부르는 OnError(ctx 없음), 둘 다 "flush: " 로 시작
+	// (bulkIndexer.onError 는 두 번째 호출을 이 접두어로 걸러 냄)
+	if !reflect.DeepEqual(onError, []bool{true, false}) {
+		t.Errorf("OnError ctx carried flush state = %v, want [true false]", onError)
+	}
+	for _, e := range errs {
+		if !strings.HasPrefix(e, "flush: ") {
+			t.Errorf("OnError err = %q, want flush: prefix", e)
+		}
+	}
+	if len(onEnd) == 0 || !onEnd[0] {
+		t.Errorf("OnFlushEnd did not get OnFlushStart ctx: %v", onEnd)
+	}
+}
@@ -0,0 +1,70 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+)
+
+// CountIndexed: 인덱스(indexName-*)에 있는 equip_id + measdate 문서 수 조회
+func CountIndexed(ctx context.Context, esClient *elasticsearch.Client, indexName, equipID, measDate string) (int, error) {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{"match_phrase": map[string]interface{}{"equip_id": equipID}},
+					map[string]interface{}{"match_phrase": map[string]interface{}{"measdate": measDate}},
+				},
+			},
+		},
+	}
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(query); err != nil {
+		return 0, fmt.Errorf("encode query: %w", err)
+	}
+
+	res, err := esClient.Count(
+		esClient.Count.WithContext(ctx),
+		esClient.Count.WithIndex(indexName+"-*"),
+		esClient.Count.WithBody(&body),
+		esClient.Count.WithIgnoreUnavailable(true),
+		esClient.Count.WithAllowNoIndices(true),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("count request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, fmt.Errorf("count request: %s", res.String())
+	}
+
+	var out struct {
+		Count int `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return 0, fmt.Errorf("decode count response: %w", err)
+	}
+	return out.Count, nil
+}
+
+// ClusterHealth: 클러스터 상태(green/yellow/red) 조회
+func ClusterHealth(ctx context.Context, esClient *elasticsearch.Client) (string, error) {
+	res, err := esClient.Cluster.Health(esClient.Cluster.Health.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("cluster health request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return "", fmt.Errorf("cluster health request: %s", res.String())
+	}
+
+	var out struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("decode cluster health response: %w", err)
+	}
+	return out.Status, nil
+}
@@ -0,0 +1,163 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+	"github.com/sirupsen/logrus"
+	"same-parser/internal/dlq"
+	"same-parser/internal/model"
+	"same-parser/internal/sink"
+)
+
+var _ sink.Sink = (*BulkSink)(nil)
+
+// Requeuer: 요청 단위로 실패한 문서를 되돌려 다시 보내는 곳 (spool.Spool)
+type Requeuer interface {
+	// Requeue: 문서를 다시 보낼 대기열에 넣음
+	Requeue(doc model.ElasticDocument) error
+	// Backoff: 출력 실패를 알려 전달을 잠시 멈춤
+	Backoff()
+	// Resume: 출력 성공을 알려 중지 시간 초기화
+	Resume()
+}
+
+// BulkSink: ES 벌크 인덱서 출력 (sink.Sink 구현).
+// 문서는 <index_name>-YYYY.MM.DD 인덱스에 ru_param/cell_num/RU_NAME/field/measdate 로 만든 ID 로 색인.
+type BulkSink struct {
+	logger    *logrus.Logger
+	indexer   esutil.BulkIndexer
+	indexName string
+	tracker   *Tracker   // nil 이면 파일별 응답 집계 안 함
+	dead      *dlq.Queue // nil 이면 실패 항목 버림
+	retry     Requeuer   // nil 이면 요청 단위 실패도 DLQ 로
+}
+
+// NewBulkSink: tracker 가 nil 이 아니면 문서의 SourceFile 기준으로 성공/실패 응답을 집계하고,
+// dead 가 nil 이 아니면 (클라이언트 재시도 후에도) 실패한 항목을 DLQ 에 기록.
+// retry 가 nil 이 아니면 벌크 요청 자체가 실패했거나(ES 중단 등) 429 로 거절된 문서는 DLQ 대신 retry 로 되돌리고 전달을 늦춤.
+func NewBulkSink(logger *logrus.Logger, indexer esutil.BulkIndexer, indexName string, tracker *Tracker, dead *dlq.Queue, retry Requeuer) *BulkSink {
+	return &BulkSink{logger: logger, indexer: indexer, indexName: indexName, tracker: tracker, dead: dead, retry: retry}
+}
+
+func (s *BulkSink) Name() string {
+	return "elasticsearch"
+}
+
+// Write: 문서를 벌크 인덱서에 추가. 인덱서 큐가 가득 차면(ES 장애 등) 블록됨.
+func (s *BulkSink) Write(ctx context.Context, doc model.ElasticDocument) error {
+	source := safeStr(doc.SourceFile)
+	if s.tracker != nil {
+		s.tracker.Added(source)
+	}
+
+	// 문서를 JSON으로 마샬(직렬화)
+	doc.RetrySink = ""
+	b, err := json.Marshal(doc)
+	if err != nil {
+		s.ackFailed(source, err.Error())
+		return fmt.Errorf("marshal error: %s %w", source, err)
+	}
+
+	measDate := safeStr(doc.MeasDate)
+	idx := s.indexName + "-" + indexDateSuffix(measDate)
+	// 문서 ID 구성 (중복 방지 목적)
+	id := safeStr(doc.RuParam) + "-" +
+		safeStr(doc.CellNum) + "-" +
+		safeStr(doc.RUName) + "-" +
+		doc.Data.Field + "-" +
+		measDate
+	// Bulk 항목 생성: 인덱스, ID, 본문과 성공/실패 콜백 포함
+	item := esutil.BulkIndexerItem{
+		Action:     "index",
+		DocumentID: id,
+		Index:      idx,
+		Body:       bytes.NewReader(b),
+
+		OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
+			if res.Status > 201 {
+				s.logger.Infof("bulk partial success status=%d id=%s idx=%s", res.Status, item.DocumentID, item.Index)
+			}
+			if s.retry != nil {
+				s.retry.Resume()
+			}
+			if s.tracker != nil {
+				s.tracker.Succeeded(source)
+			}
+		},
+		OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+			var reason string
+			if err != nil {
+				reason = err.Error()
+			} else {
+				reason = res.Error.Type + ": " + res.Error.Reason
+			}
+			if (res.Status == 0 || res.Status == 429) && s.requeue(doc, source) {
+				s.logger.Debugf("bulk failure requeued status=%d id=%s idx=%s source=%s: %s", res.Status, item.DocumentID, item.Index, source, reason)
+				return
+			}
+			s.logger.Errorf("bulk failure status=%d id=%s idx=%s source=%s: %s", res.Status, item.DocumentID, item.Index, source, reason)
+			deadLetter(s.logger, s.dead, idx, id, b, reason, source)
+			s.ackFailed(source, reason)
+		},
+	}
+
+	if err := s.indexer.Add(ctx, item); err != nil {
+		// 종료 중(인덱서 Close 이후)이면 스풀에 남겨 다음 실행에서 전달
+		if s.requeue(doc, source) {
+			return nil
+		}
+		deadLetter(s.logger, s.dead, idx, id, b, err.Error(), source)
+		s.ackFailed(source, err.Error())
+		return fmt.Errorf("indexer.Add failed: id=%s idx=%s err=%w", id, idx, err)
+	}
+	return nil
+}
+
+// Flush: esutil.BulkIndexer 는 명시적 flush 가 없으므로 flush_interval/flush_bytes 에 맡김
+func (s *BulkSink) Flush(context.Context) error {
+	return nil
+}
+
+// Close: 남은 버퍼 flush 후 인덱서 종료, 최종 BulkIndexerStats 로그
+func (s *BulkSink) Close(ctx context.Context) error {
+	err := s.indexer.Close(ctx)
+	stats := s.indexer.Stats()
+	s.logger.Infof("벌크 인덱서 통계: added=%d flushed=%d failed=%d indexed=%d created=%d updated=%d requests=%d",
+		stats.NumAdded, stats.NumFlushed, stats.NumFailed, stats.NumIndexed, stats.NumCreated, stats.NumUpdated, stats.NumRequests)
+	if err != nil {
+		return fmt.Errorf("벌크 인덱서 종료 실패: %w", err)
+	}
+	return nil
+}
+
+// Stats: 인덱서 누적 통계 (succeeded 는 flush 성공 건수)
+func (s *BulkSink) Stats() sink.Stats {
+	st := s.indexer.Stats()
+	return sink.Stats{Name: s.Name(), Written: st.NumAdded, Succeeded: st.NumFlushed, Failed: st.NumFailed}
+}
+
+// requeue: 요청 단위로 실패한 문서를 retry 로 되돌리고 전달을 늦춤. 되돌리지 못하면 false (DLQ 로).
+func (s *BulkSink) requeue(doc model.ElasticDocument, source string) bool {
+	if s.retry == nil {
+		return false
+	}
+	doc.RetrySink = s.Name()
+	if err := s.retry.Requeue(doc); err != nil {
+		s.logger.Errorf("실패 문서 스풀 재기록 실패: source=%s err=%v", source, err)
+		return false
+	}
+	s.retry.Backoff()
+	if s.tracker != nil {
+		s.tracker.Requeued(source)
+	}
+	return true
+}
+
+func (s *BulkSink) ackFailed(source, reason string) {
+	if s.tracker != nil {
+		s.tracker.Failed(source, reason)
+	}
+}
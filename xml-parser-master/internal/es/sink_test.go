@@ -0,0 +1,307 @@
+package es
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"same-parser/internal/dlq"
+	"same-parser/internal/model"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBulkServer: _bulk 요청의 항목을 모두 성공(201)으로 응답하는 ES 흉내
+func fakeBulkServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return fakeBulkServerFunc(t, nil)
+}
+
+// fakeBulkServerFunc: fakeBulkServer 와 같고, onBulk 가 있으면 _bulk 요청마다 항목 _id 목록을 넘김
+func fakeBulkServerFunc(t *testing.T, onBulk func(ids []string)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasSuffix(r.URL.Path, "/_bulk") {
+			io.WriteString(w, `{"version":{"number":"7.17.10"},"tagline":"You Know, for Search"}`)
+			return
+		}
+		var items []map[string]interface{}
+		var ids []string
+		sc := bufio.NewScanner(r.Body)
+		sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+		for sc.Scan() {
+			var meta map[string]map[string]string
+			if err := json.Unmarshal(sc.Bytes(), &meta); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			sc.Scan() // 본문
+			ids = append(ids, meta["index"]["_id"])
+			items = append(items, map[string]interface{}{
+				"index": map[string]interface{}{"_index": meta["index"]["_index"], "_id": meta["index"]["_id"], "status": 201, "result": "created"},
+			})
+		}
+		if onBulk != nil {
+			onBulk(ids)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"took": 1, "errors": false, "items": items})
+	}))
+}
+
+func testDoc(source string, i int) model.ElasticDocument {
+	str := func(s string) *string { return &s }
+	return model.ElasticDocument{
+		RuParam:    str(fmt.Sprintf("DU1/RU%d", i)),
+		Data:       model.Data{Field: "PRBDL", Result: i},
+		MeasDate:   str("202405011015"),
+		SourceFile: str(source),
+	}
+}
+
+// waitFor: cond 가 참이 될 때까지 대기
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestBulkSinkTransportClosed: 파일 처리 도중 ES 연결이 끊기면 요청 단위로 실패한 문서도
+// DLQ 에 기록되고 Tracker 에 실패로 응답되어, 파일이 완료 훅까지 도달해야 함.
+func TestBulkSinkTransportClosed(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	srv := fakeBulkServer(t)
+	defer srv.Close()
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}, DisableRetry: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flush_bytes 1: 항목마다 바로 벌크 요청
+	indexer, err := newBulkIndexer(logger, client, "kpi", 2, 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead, err := dlq.Open(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := NewTracker()
+	s := NewBulkSink(logger, indexer, "kpi", tracker, dead, nil)
+
+	const file = "/scan/A.xml"
+	tracker.Begin(file)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := s.Write(ctx, testDoc(file, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, "first documents indexed", func() bool {
+		snap := tracker.Snapshot()
+		return len(snap) == 1 && snap[0].Succeeded == 3
+	})
+
+	// 파일 중간에 ES 연결 끊김
+	srv.Close()
+	for i := 3; i < 8; i++ {
+		if err := s.Write(ctx, testDoc(file, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done := make(chan FileStatus, 1)
+	tracker.Seal(file, 8, func(st FileStatus) { done <- st })
+	var st FileStatus
+	select {
+	case st = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("file never completed: %+v", tracker.Snapshot())
+	}
+	if st.Succeeded != 3 || st.Failed != 5 || st.Pending != 0 {
+		t.Errorf("status = succeeded %d failed %d pending %d, want 3/5/0", st.Succeeded, st.Failed, st.Pending)
+	}
+	if len(st.Errors) == 0 || !strings.Contains(st.Errors[0], "flush") {
+		t.Errorf("errors = %v, want flush failure reason", st.Errors)
+	}
+
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := dead.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countDLQ(t, dead.Dir()); n != 5 {
+		t.Errorf("dlq records = %d, want 5", n)
+	}
+	if stats := indexer.Stats(); stats.NumFlushed != 3 || stats.NumFailed != 5 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+// TestBulkIndexerCloseExpired: Close 제한 시간이 이미 지났어도 버퍼에 남은 항목은 실패로 응답되어야 함
+func TestBulkIndexerCloseExpired(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	srv := fakeBulkServer(t)
+	defer srv.Close()
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}, DisableRetry: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexer, err := newBulkIndexer(logger, client, "kpi", 2, 5<<20, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := NewTracker()
+	s := NewBulkSink(logger, indexer, "kpi", tracker, nil, nil)
+
+	const file = "/scan/B.xml"
+	tracker.Begin(file)
+	done := make(chan FileStatus, 1)
+	for i := 0; i < 4; i++ {
+		if err := s.Write(context.Background(), testDoc(file, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tracker.Seal(file, 4, func(st FileStatus) { done <- st })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Close(ctx)
+	select {
+	case st := <-done:
+		if st.Succeeded+st.Failed != 4 {
+			t.Errorf("status = %+v", st)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("file never completed: %+v", tracker.Snapshot())
+	}
+
+	// 종료 후에도 출력 워커가 넘기는 문서는 panic 없이 거절
+	if err := s.Write(context.Background(), testDoc(file, 9)); !errors.Is(err, errClosed) {
+		t.Errorf("Write after Close err = %v, want errClosed", err)
+	}
+}
+
+func countDLQ(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		n += strings.Count(string(b), "\n")
+	}
+	return n
+}
+
+// fakeRequeuer: 되돌린 문서와 Backoff/Resume 호출 기록
+type fakeRequeuer struct {
+	mu      sync.Mutex
+	docs    []model.ElasticDocument
+	backoff int
+	resume  int
+}
+
+func (r *fakeRequeuer) Requeue(doc model.ElasticDocument) error {
+	r.mu.Lock()
+	r.docs = append(r.docs, doc)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *fakeRequeuer) Backoff() {
+	r.mu.Lock()
+	r.backoff++
+	r.mu.Unlock()
+}
+
+func (r *fakeRequeuer) Resume() {
+	r.mu.Lock()
+	r.resume++
+	r.mu.Unlock()
+}
+
+// TestBulkSinkRequeue: 스풀이 있으면 요청 단위로 실패한 문서는 DLQ 대신 스풀로 되돌아가고, 파일은 응답 대기로 남아야 함
+func TestBulkSinkRequeue(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	srv := fakeBulkServer(t)
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}, DisableRetry: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close() // 처음부터 ES 중단
+	indexer, err := newBulkIndexer(logger, client, "kpi", 2, 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead, err := dlq.Open(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := NewTracker()
+	retry := &fakeRequeuer{}
+	s := NewBulkSink(logger, indexer, "kpi", tracker, dead, retry)
+
+	const file = "/scan/C.xml"
+	tracker.Begin(file)
+	for i := 0; i < 3; i++ {
+		doc := testDoc(file, i)
+		doc.RetrySink = s.Name() // 이전에 되돌린 문서도 그대로 다시 보냄
+		if err := s.Write(context.Background(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tracker.Seal(file, 3, nil)
+	waitFor(t, "documents requeued", func() bool {
+		retry.mu.Lock()
+		defer retry.mu.Unlock()
+		return len(retry.docs) == 3
+	})
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := tracker.Snapshot()
+	if len(snap) != 1 || snap[0].Pending != 0 || snap[0].Failed != 0 || snap[0].Succeeded != 0 {
+		t.Errorf("tracker = %+v, want file waiting for requeued documents", snap)
+	}
+	for _, doc := range retry.docs {
+		if doc.RetrySink != "elasticsearch" {
+			t.Errorf("requeued doc retry_sink = %q", doc.RetrySink)
+		}
+	}
+	if retry.backoff != 3 || retry.resume != 0 {
+		t.Errorf("backoff = %d resume = %d", retry.backoff, retry.resume)
+	}
+	dead.Close()
+	if n := countDLQ(t, dead.Dir()); n != 0 {
+		t.Errorf("dlq records = %d, want 0", n)
+	}
+}
@@ -0,0 +1,154 @@
+package es
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxFileErrors: 파일별로 보관하는 실패 사유 최대 개수
+const maxFileErrors = 5
+
+// FileStatus: 원본 파일 하나의 벌크 색인 진행 상황
+type FileStatus struct {
+	File      string    `json:"file"`
+	Expected  int       `json:"expected"` // Seal 로 확정된 전송 문서 수 (Seal 전에는 0)
+	Pending   int       `json:"pending"`  // 벌크 인덱서에 넣었으나 응답 대기 중
+	Succeeded int       `json:"succeeded"`
+	Failed    int       `json:"failed"`
+	Sealed    bool      `json:"sealed"`
+	Errors    []string  `json:"errors,omitempty"` // 앞쪽 실패 사유 일부
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// Done: 전송 문서 수가 확정되었고 모든 응답이 도착했는지
+func (s FileStatus) Done() bool {
+	return s.Sealed && s.Succeeded+s.Failed >= s.Expected
+}
+
+type fileState struct {
+	FileStatus
+	onComplete func(FileStatus)
+}
+
+// Tracker: ElasticDocument.SourceFile 기준으로 출력 대상(ES 벌크 OnSuccess/OnFailure 등)의 응답을 집계하고,
+// 파일의 모든 문서가 응답되면 Seal 에서 등록한 완료 훅을 한 번 호출.
+type Tracker struct {
+	mu    sync.Mutex
+	files map[string]*fileState
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{files: make(map[string]*fileState)}
+}
+
+// Begin: 파일 처리 시작 등록. 이미 처리 중인 파일이면 false. 등록되지 않은 파일의 응답은 무시.
+func (t *Tracker) Begin(file string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.files[file]; ok {
+		return false
+	}
+	t.files[file] = &fileState{FileStatus: FileStatus{File: file, StartedAt: time.Now()}}
+	return true
+}
+
+// Discard: 완료 훅 없이 파일 기록 제거 (재시도 전 등)
+func (t *Tracker) Discard(file string) {
+	t.mu.Lock()
+	delete(t.files, file)
+	t.mu.Unlock()
+}
+
+// Seal: 파일에서 전송한 문서 수를 확정하고 완료 훅 등록.
+// 이미 모든 응답이 도착했으면 바로 훅 호출.
+func (t *Tracker) Seal(file string, expected int, onComplete func(FileStatus)) {
+	t.mu.Lock()
+	st, ok := t.files[file]
+	if !ok {
+		st = &fileState{FileStatus: FileStatus{File: file, StartedAt: time.Now()}}
+		t.files[file] = st
+	}
+	st.Expected, st.Sealed, st.onComplete = expected, true, onComplete
+	t.mu.Unlock()
+	t.check(file)
+}
+
+// Snapshot: 아직 완료되지 않은 파일 목록 (시작 순)
+func (t *Tracker) Snapshot() []FileStatus {
+	t.mu.Lock()
+	out := make([]FileStatus, 0, len(t.files))
+	for _, st := range t.files {
+		s := st.FileStatus
+		s.Errors = append([]string(nil), st.Errors...)
+		out = append(out, s)
+	}
+	t.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out
+}
+
+// Added: 출력 대상에 문서 추가 (응답 대기)
+func (t *Tracker) Added(file string) {
+	t.mu.Lock()
+	if st, ok := t.files[file]; ok {
+		st.Pending++
+	}
+	t.mu.Unlock()
+}
+
+// Succeeded: 문서 기록 성공 응답
+func (t *Tracker) Succeeded(file string) {
+	t.mu.Lock()
+	st, ok := t.files[file]
+	if ok {
+		st.Pending--
+		st.Succeeded++
+	}
+	t.mu.Unlock()
+	if ok {
+		t.check(file)
+	}
+}
+
+// Requeued: 요청 단위로 실패한 문서를 스풀로 되돌림 (다시 Write 되면 Added 로 응답 대기)
+func (t *Tracker) Requeued(file string) {
+	t.mu.Lock()
+	if st, ok := t.files[file]; ok {
+		st.Pending--
+	}
+	t.mu.Unlock()
+}
+
+// Failed: 문서 기록 실패 응답
+func (t *Tracker) Failed(file, reason string) {
+	t.mu.Lock()
+	st, ok := t.files[file]
+	if ok {
+		st.Pending--
+		st.Failed++
+		if len(st.Errors) < maxFileErrors {
+			st.Errors = append(st.Errors, reason)
+		}
+	}
+	t.mu.Unlock()
+	if ok {
+		t.check(file)
+	}
+}
+
+// check: 완료된 파일이면 기록 제거 후 훅 호출
+func (t *Tracker) check(file string) {
+	t.mu.Lock()
+	st, ok := t.files[file]
+	if !ok || !st.Done() {
+		t.mu.Unlock()
+		return
+	}
+	delete(t.files, file)
+	t.mu.Unlock()
+
+	if st.onComplete != nil {
+		st.onComplete(st.FileStatus)
+	}
+}
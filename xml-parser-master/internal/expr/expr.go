@@ -0,0 +1,397 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrDivByZero: 0으로 나누기가 발생했을 때 Eval이 반환하는 오류
+var ErrDivByZero = errors.New("division by zero")
+
+// Vars: 식별자(카운터 이름) → 값 조회 함수
+type Vars func(name string) float64
+
+// Expr: 컴파일된 산술식.
+// 지원 문법: 숫자, 식별자, + - * / %, 단항 -, 괄호, 함수 min/max/abs/sum
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile: 식 문자열을 파싱해 Expr 생성
+func Compile(src string) (*Expr, error) {
+	p := &exprParser{src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String: 원본 식 문자열
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Idents: 식에 사용된 식별자 목록 (중복 제거, 등장 순서)
+func (e *Expr) Idents() []string {
+	var out []string
+	seen := make(map[string]bool)
+	walk(e.root, func(n node) {
+		if id, ok := n.(ident); ok && !seen[string(id)] {
+			seen[string(id)] = true
+			out = append(out, string(id))
+		}
+	})
+	return out
+}
+
+// Eval: vars로 식별자 값을 조회해 식을 계산
+func (e *Expr) Eval(vars Vars) (float64, error) {
+	return e.root.eval(vars)
+}
+
+// ---------------------------------------------------------------------------
+// AST
+// ---------------------------------------------------------------------------
+
+type node interface {
+	eval(vars Vars) (float64, error)
+}
+
+type number float64
+
+func (n number) eval(Vars) (float64, error) { return float64(n), nil }
+
+type ident string
+
+func (n ident) eval(vars Vars) (float64, error) { return vars(string(n)), nil }
+
+type unary struct {
+	x node
+}
+
+func (n unary) eval(vars Vars) (float64, error) {
+	v, err := n.x.eval(vars)
+	return -v, err
+}
+
+type binary struct {
+	op   byte
+	l, r node
+}
+
+func (n binary) eval(vars Vars) (float64, error) {
+	l, err := n.l.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.r.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, ErrDivByZero
+		}
+		return l / r, nil
+	case '%':
+		if r == 0 {
+			return 0, ErrDivByZero
+		}
+		return math.Mod(l, r), nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", n.op)
+}
+
+type call struct {
+	name string
+	args []node
+}
+
+func (n call) eval(vars Vars) (float64, error) {
+	vals := make([]float64, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		vals[i] = v
+	}
+	switch n.name {
+	case "abs":
+		return math.Abs(vals[0]), nil
+	case "min":
+		m := vals[0]
+		for _, v := range vals[1:] {
+			m = math.Min(m, v)
+		}
+		return m, nil
+	case "max":
+		m := vals[0]
+		for _, v := range vals[1:] {
+			m = math.Max(m, v)
+		}
+		return m, nil
+	case "sum":
+		var s float64
+		for _, v := range vals {
+			s += v
+		}
+		return s, nil
+	}
+	return 0, fmt.Errorf("unknown function %s", n.name)
+}
+
+// funcArity: 지원 함수와 최소/최대 인자 수 (-1: 제한 없음)
+var funcArity = map[string][2]int{
+	"abs": {1, 1},
+	"min": {1, -1},
+	"max": {1, -1},
+	"sum": {1, -1},
+}
+
+func walk(n node, fn func(node)) {
+	fn(n)
+	switch n := n.(type) {
+	case unary:
+		walk(n.x, fn)
+	case binary:
+		walk(n.l, fn)
+		walk(n.r, fn)
+	case call:
+		for _, a := range n.args {
+			walk(a, fn)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// 토크나이저 / 파서 (재귀 하강)
+// ---------------------------------------------------------------------------
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+type exprParser struct {
+	src string
+	pos int
+	tok token
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("expr %q at %d: %s", p.src, p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+// next: 다음 토큰을 읽어 p.tok에 저장
+func (p *exprParser) next() error {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok = token{kind: tokNum, text: p.src[start:p.pos], pos: start}
+	case isIdentStart(c):
+		for p.pos < len(p.src) && isIdentPart(p.src[p.pos]) {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: p.src[start:p.pos], pos: start}
+	case strings.IndexByte("+-*/%(),", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokOp, text: string(c), pos: start}
+	default:
+		p.tok = token{pos: start}
+		return p.errorf("unexpected character %q", c)
+	}
+	return nil
+}
+
+func (p *exprParser) isOp(op string) bool {
+	return p.tok.kind == tokOp && p.tok.text == op
+}
+
+// expr := term (('+'|'-') term)*
+func (p *exprParser) parseExpr() (node, error) {
+	l, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.tok.text[0]
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+// term := unary (('*'|'/'|'%') unary)*
+func (p *exprParser) parseTerm() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*") || p.isOp("/") || p.isOp("%") {
+		op := p.tok.text[0]
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+// unary := '-' unary | primary
+func (p *exprParser) parseUnary() (node, error) {
+	if p.isOp("-") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unary{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+// primary := number | ident | ident '(' args ')' | '(' expr ')'
+func (p *exprParser) parsePrimary() (node, error) {
+	switch {
+	case p.tok.kind == tokNum:
+		f, err := strconv.ParseFloat(p.tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %q", p.tok.text)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return number(f), nil
+
+	case p.tok.kind == tokIdent:
+		name := p.tok.text
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if !p.isOp("(") {
+			return ident(name), nil
+		}
+		return p.parseCall(name)
+
+	case p.isOp("("):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOp(")") {
+			return nil, p.errorf("expected )")
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+	if p.tok.kind == tokEOF {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected %q", p.tok.text)
+}
+
+func (p *exprParser) parseCall(name string) (node, error) {
+	arity, ok := funcArity[name]
+	if !ok {
+		return nil, p.errorf("unknown function %s", name)
+	}
+	if err := p.next(); err != nil { // '('
+		return nil, err
+	}
+	var args []node
+	for !p.isOp(")") {
+		a, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+		if p.isOp(",") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			// ',' 뒤에는 인자가 와야 함 (max(a,) 거부)
+			if p.isOp(")") {
+				return nil, p.errorf("expected argument after ,")
+			}
+		} else if !p.isOp(")") {
+			return nil, p.errorf("expected , or )")
+		}
+	}
+	if err := p.next(); err != nil { // ')'
+		return nil, err
+	}
+	if len(args) < arity[0] || (arity[1] >= 0 && len(args) > arity[1]) {
+		return nil, p.errorf("%s: wrong number of arguments (%d)", name, len(args))
+	}
+	return call{name: name, args: args}, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
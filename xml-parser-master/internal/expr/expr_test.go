@@ -0,0 +1,128 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mapVars: 맵에 없는 카운터는 0 (rules.MetricRule.Evaluate 와 같은 규칙)
+func mapVars(m map[string]float64) Vars {
+	return func(name string) float64 { return m[name] }
+}
+
+func TestEval(t *testing.T) {
+	vars := mapVars(map[string]float64{"a": 6, "b": 3, "c": 2, "zero": 0})
+	cases := []struct {
+		src  string
+		want float64
+	}{
+		// 우선순위
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"a - b - c", 1},
+		{"a / b / c", 1},
+		{"a + b * c - 4 / 2", 10},
+		{"a % 4 * 2", 4},
+		{"-a + b", -3},
+		{"-(a + b)", -9},
+		{"--a", 6},
+		{"2 * -b", -6},
+		// 함수
+		{"min(a, b, c)", 2},
+		{"max(a, b * 3)", 9},
+		{"abs(b - a)", 3},
+		{"sum(a, b, c) / 11", 1},
+		{"max(min(a, b), c)", 3},
+		// 숫자
+		{"1.5 * c", 3},
+		{".5 + .5", 1},
+		// 없는 카운터는 0
+		{"missing + a", 6},
+		{"missing * a", 0},
+		// RRC 성공률 형태
+		{"100 * a / (a + zero)", 100},
+	}
+	for _, tc := range cases {
+		e, err := Compile(tc.src)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tc.src, err)
+			continue
+		}
+		got, err := e.Eval(vars)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tc.src, err)
+			continue
+		}
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Eval(%q) = %v, want %v", tc.src, got, tc.want)
+		}
+	}
+}
+
+func TestEvalDivByZero(t *testing.T) {
+	vars := mapVars(map[string]float64{"a": 1, "zero": 0})
+	for _, src := range []string{
+		"a / 0",
+		"a % 0",
+		"a / zero",
+		"a / missing",         // 없는 카운터는 0
+		"100 * a / (a - a)",   // 계산 결과가 0
+		"max(a, a / missing)", // 함수 인자 안
+		"-(a / zero)",
+	} {
+		e, err := Compile(src)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", src, err)
+			continue
+		}
+		if _, err := e.Eval(vars); !errors.Is(err, ErrDivByZero) {
+			t.Errorf("Eval(%q) err = %v, want ErrDivByZero", src, err)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string // 오류 메시지에 포함될 문자열
+	}{
+		{"", "unexpected end of expression"},
+		{"a +", "unexpected end of expression"},
+		{"(a + b", "expected )"},
+		{"a b", `unexpected "b"`},
+		{"a $ b", "unexpected character"},
+		{"1..2", "bad number"},
+		{"foo(a)", "unknown function foo"},
+		{"max()", "wrong number of arguments (0)"},
+		{"abs(a, b)", "wrong number of arguments (2)"},
+		{"max(a,)", "expected argument after ,"},
+		{"max(a, b,)", "expected argument after ,"},
+		{"max(,a)", `unexpected ","`},
+		{"max(a b)", "expected , or )"},
+		{"max(a", "expected , or )"},
+	}
+	for _, tc := range cases {
+		_, err := Compile(tc.src)
+		if err == nil {
+			t.Errorf("Compile(%q): expected error", tc.src)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Compile(%q) err = %v, want %q", tc.src, err, tc.want)
+		}
+	}
+}
+
+func TestIdents(t *testing.T) {
+	e, err := Compile("100 * succ / (att + max(att, retry) - succ)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"succ", "att", "retry"}
+	if got := e.Idents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Idents = %v, want %v", got, want)
+	}
+}
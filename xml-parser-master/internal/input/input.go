@@ -0,0 +1,243 @@
+package input
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// supportedSuffixes: 감시 대상 파일 확장자 (소문자)
+var supportedSuffixes = []string{".xml", ".xml.gz", ".gz", ".zip", ".tar.gz", ".tgz"}
+
+// Supported: 파일 이름이 처리 가능한 입력(XML 또는 압축 묶음)인지 여부
+func Supported(name string) bool {
+	lower := strings.ToLower(name)
+	for _, s := range supportedSuffixes {
+		if strings.HasSuffix(lower, s) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// ErrCorrupt: 압축/묶음 구조가 깨짐 (잘린 gzip, 손상된 tar/zip 헤더 등). 다시 읽어도 결과가 같음.
+	ErrCorrupt = errors.New("corrupt archive")
+	// ErrUnsupported: 처리할 수 없는 묶음 구조 (스트림 안의 zip 등)
+	ErrUnsupported = errors.New("unsupported archive layout")
+)
+
+// MemberError: 묶음 파일 안의 XML 하나를 처리하다 난 오류
+type MemberError struct {
+	Member string // 예: bundle.tar.gz!DU1.xml
+	Err    error
+}
+
+func (e *MemberError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Member, e.Err)
+}
+
+func (e *MemberError) Unwrap() error {
+	return e.Err
+}
+
+// MemberFunc: 압축 해제된 XML 스트림 하나를 처리하는 콜백. name 은 "파일!멤버" 형식.
+type MemberFunc func(name string, r io.Reader) error
+
+// Walk: 파일을 열어 매직 바이트(없으면 확장자)로 형식을 판별하고, 포함된 XML 마다 fn 호출.
+// 디스크에 풀지 않고 스트리밍으로 처리하며, 멤버별 오류는 []*MemberError 로 모아 반환.
+// 파일 자체를 열 수 없거나 묶음 구조가 깨진 경우에만 error 반환.
+// 구조 손상은 ErrCorrupt, 지원하지 않는 구조는 ErrUnsupported 로 감싸므로 errors.Is 로 구분 (그 외는 입출력 오류).
+func Walk(filename string, fn MemberFunc) ([]*MemberError, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	w := &walker{fn: fn}
+	base := path.Base(filename)
+
+	br := bufio.NewReader(f)
+	switch sniff(br, base) {
+	case kindZip:
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		err = w.zip(base, f, fi.Size())
+		return w.errs, err
+	default:
+		err = w.stream(base, br)
+		return w.errs, err
+	}
+}
+
+type kind int
+
+const (
+	kindXML kind = iota
+	kindGzip
+	kindZip
+	kindTar
+)
+
+// sniff: 앞부분 매직 바이트로 형식 판별 (읽은 바이트는 소비하지 않음)
+func sniff(br *bufio.Reader, name string) kind {
+	head, _ := br.Peek(262)
+	switch {
+	case len(head) >= 2 && head[0] == 0x1f && head[1] == 0x8b:
+		return kindGzip
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return kindZip
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return kindTar
+	}
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return kindZip
+	case strings.HasSuffix(lower, ".tar"):
+		return kindTar
+	}
+	return kindXML
+}
+
+type walker struct {
+	fn   MemberFunc
+	errs []*MemberError
+}
+
+// stream: 순차 스트림(XML, gzip, tar) 처리. gzip 안의 tar 도 다시 판별.
+func (w *walker) stream(name string, r io.Reader) error {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	switch sniff(br, name) {
+	case kindGzip:
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("%s: gzip: %w", name, classify(err))
+		}
+		defer zr.Close()
+		return w.stream(name, zr)
+	case kindTar:
+		return w.tar(name, br)
+	case kindZip:
+		// 스트림 안의 zip 은 임의 접근이 불가하므로 지원하지 않음
+		w.errs = append(w.errs, &MemberError{Member: name, Err: fmt.Errorf("nested zip: %w", ErrUnsupported)})
+		return nil
+	default:
+		w.call(name, br)
+		return nil
+	}
+}
+
+func (w *walker) tar(name string, r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: tar: %w", name, classify(err))
+		}
+		if hdr.Typeflag != tar.TypeReg || !isXMLMember(hdr.Name) {
+			continue
+		}
+		if err := w.stream(name+"!"+hdr.Name, tr); err != nil {
+			w.errs = append(w.errs, &MemberError{Member: name + "!" + hdr.Name, Err: err})
+		}
+	}
+}
+
+func (w *walker) zip(name string, ra io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return fmt.Errorf("%s: zip: %w", name, classify(err))
+	}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || !isXMLMember(zf.Name) {
+			continue
+		}
+		member := name + "!" + zf.Name
+		rc, err := zf.Open()
+		if err != nil {
+			w.errs = append(w.errs, &MemberError{Member: member, Err: classify(err)})
+			continue
+		}
+		if err := w.stream(member, rc); err != nil {
+			w.errs = append(w.errs, &MemberError{Member: member, Err: err})
+		}
+		rc.Close()
+	}
+	return nil
+}
+
+// call: 콜백 실행, 오류는 멤버 오류로 기록.
+// 콜백이 읽던 압축 스트림이 깨져서 실패했으면 ErrCorrupt 로 감쌈.
+func (w *walker) call(name string, r io.Reader) {
+	tr := &trackReader{r: r}
+	if err := w.fn(name, tr); err != nil {
+		if tr.err != nil && isCorrupt(tr.err) {
+			err = fmt.Errorf("%w: %w", ErrCorrupt, err)
+		}
+		w.errs = append(w.errs, &MemberError{Member: name, Err: err})
+	}
+}
+
+// trackReader: 읽기 중 처음 난 오류(EOF 제외)를 기록
+type trackReader struct {
+	r   io.Reader
+	err error
+}
+
+func (t *trackReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err != nil && err != io.EOF && t.err == nil {
+		t.err = err
+	}
+	return n, err
+}
+
+// classify: 구조 손상 오류면 ErrCorrupt, 지원하지 않는 압축 방식이면 ErrUnsupported 로 감쌈 (그 외 입출력 오류는 그대로)
+func classify(err error) error {
+	switch {
+	case errors.Is(err, zip.ErrAlgorithm):
+		return fmt.Errorf("%w: %w", ErrUnsupported, err)
+	case isCorrupt(err):
+		return fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	return err
+}
+
+// isCorrupt: 압축/묶음 형식 오류 또는 데이터가 중간에 끊긴 경우
+func isCorrupt(err error) bool {
+	var flateErr flate.CorruptInputError
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, gzip.ErrHeader), errors.Is(err, gzip.ErrChecksum),
+		errors.Is(err, tar.ErrHeader),
+		errors.Is(err, zip.ErrFormat), errors.Is(err, zip.ErrChecksum),
+		errors.As(err, &flateErr):
+		return true
+	}
+	return false
+}
+
+// isXMLMember: 묶음 안에서 처리할 멤버인지 (xml, xml.gz)
+func isXMLMember(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".xml") || strings.HasSuffix(lower, ".xml.gz")
+}
@@ -0,0 +1,288 @@
+package input
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleXML = `<?xml version="1.0"?><measCollecFile><measData><managedElement localDn="DU1"/></measData></measCollecFile>`
+
+// walkResult: Walk 가 넘긴 멤버 이름 → 내용
+type walkResult struct {
+	members map[string]string
+	order   []string
+}
+
+// walkFile: content 를 name 으로 저장해 Walk 실행. 콜백은 멤버를 끝까지 읽고 읽기 오류를 그대로 반환.
+func walkFile(t *testing.T, name string, content []byte) (*walkResult, []*MemberError, error) {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res := &walkResult{members: make(map[string]string)}
+	errs, err := Walk(p, func(member string, r io.Reader) error {
+		b, err := io.ReadAll(r)
+		res.members[member] = string(b)
+		res.order = append(res.order, member)
+		return err
+	})
+	return res, errs, err
+}
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+type archiveEntry struct {
+	name string
+	body []byte
+	dir  bool
+}
+
+func tarBytes(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.dir {
+			hdr = &tar.Header{Name: e.name, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(e.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(e.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWalkXML(t *testing.T) {
+	res, errs, err := walkFile(t, "A.xml", []byte(sampleXML))
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("err=%v member errs=%v", err, errs)
+	}
+	if got := res.members["A.xml"]; got != sampleXML {
+		t.Errorf("content = %q", got)
+	}
+}
+
+func TestWalkGzip(t *testing.T) {
+	res, errs, err := walkFile(t, "A.xml.gz", gzipBytes(t, []byte(sampleXML)))
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("err=%v member errs=%v", err, errs)
+	}
+	if got := res.members["A.xml.gz"]; got != sampleXML {
+		t.Errorf("content = %q", got)
+	}
+}
+
+func TestWalkTruncatedGzip(t *testing.T) {
+	full := gzipBytes(t, []byte(strings.Repeat(sampleXML, 50)))
+
+	// 본문 중간에서 끊김: 콜백의 읽기 실패 → 멤버 오류 (ErrCorrupt)
+	_, errs, err := walkFile(t, "body.xml.gz", full[:len(full)/2])
+	if err != nil {
+		t.Fatalf("Walk err = %v, want member error", err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrCorrupt) || !errors.Is(errs[0], io.ErrUnexpectedEOF) {
+		t.Fatalf("member errs = %v, want ErrCorrupt", errs)
+	}
+
+	// 헤더에서 끊김: 묶음 오류 (ErrCorrupt)
+	_, errs, err = walkFile(t, "head.xml.gz", full[:5])
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Walk err = %v, want ErrCorrupt", err)
+	}
+	if len(errs) > 0 {
+		t.Errorf("member errs = %v", errs)
+	}
+}
+
+func TestWalkNestedZip(t *testing.T) {
+	inner := zipBytes(t, []archiveEntry{{name: "A.xml", body: []byte(sampleXML)}})
+
+	// gzip 안의 zip: 임의 접근 불가 → ErrUnsupported
+	res, errs, err := walkFile(t, "bundle.zip.gz", gzipBytes(t, inner))
+	if err != nil {
+		t.Fatalf("Walk err = %v", err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrUnsupported) || errs[0].Member != "bundle.zip.gz" {
+		t.Fatalf("member errs = %v, want ErrUnsupported", errs)
+	}
+	if len(res.members) != 0 {
+		t.Errorf("members = %v, want none", res.order)
+	}
+
+	// zip 안의 zip: 그 멤버만 ErrUnsupported, 나머지 멤버는 처리
+	outer := zipBytes(t, []archiveEntry{
+		{name: "inner.xml", body: inner},
+		{name: "B.xml", body: []byte(sampleXML)},
+	})
+	res, errs, err = walkFile(t, "bundle.zip", outer)
+	if err != nil {
+		t.Fatalf("Walk err = %v", err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrUnsupported) || errs[0].Member != "bundle.zip!inner.xml" {
+		t.Fatalf("member errs = %v, want ErrUnsupported for inner.xml", errs)
+	}
+	if !reflect.DeepEqual(res.order, []string{"bundle.zip!B.xml"}) {
+		t.Errorf("members = %v", res.order)
+	}
+}
+
+func TestWalkMixedTar(t *testing.T) {
+	nestedZip := zipBytes(t, []archiveEntry{{name: "Z.xml", body: []byte(sampleXML)}})
+	tgz := gzipBytes(t, tarBytes(t, []archiveEntry{
+		{name: "dir/", dir: true},
+		{name: "dir/A.xml", body: []byte(sampleXML)},
+		{name: "README.txt", body: []byte("not xml")},
+		{name: "B.xml.gz", body: gzipBytes(t, []byte(sampleXML))},
+		{name: "C.xml", body: nestedZip},
+		{name: "D.XML", body: []byte(sampleXML)},
+	}))
+
+	res, errs, err := walkFile(t, "bundle.tar.gz", tgz)
+	if err != nil {
+		t.Fatalf("Walk err = %v", err)
+	}
+	want := []string{"bundle.tar.gz!dir/A.xml", "bundle.tar.gz!B.xml.gz", "bundle.tar.gz!D.XML"}
+	if !reflect.DeepEqual(res.order, want) {
+		t.Errorf("members = %v, want %v", res.order, want)
+	}
+	for _, m := range want {
+		if res.members[m] != sampleXML {
+			t.Errorf("%s content = %q", m, res.members[m])
+		}
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrUnsupported) || errs[0].Member != "bundle.tar.gz!C.xml" {
+		t.Errorf("member errs = %v, want ErrUnsupported for C.xml", errs)
+	}
+}
+
+func TestWalkCorruptTar(t *testing.T) {
+	raw := tarBytes(t, []archiveEntry{
+		{name: "A.xml", body: []byte(sampleXML)},
+		{name: "B.xml", body: []byte(sampleXML)},
+	})
+	// 두 번째 헤더의 체크섬 깨뜨림 (첫 헤더 512 + 본문 512 바이트 뒤)
+	raw[1024+148] ^= 0x7f
+
+	res, _, err := walkFile(t, "bundle.tgz", gzipBytes(t, raw))
+	if !errors.Is(err, ErrCorrupt) || !errors.Is(err, tar.ErrHeader) {
+		t.Fatalf("Walk err = %v, want ErrCorrupt", err)
+	}
+	if !reflect.DeepEqual(res.order, []string{"bundle.tgz!A.xml"}) {
+		t.Errorf("members = %v", res.order)
+	}
+
+	// 헤더 중간에서 잘린 tar: ErrCorrupt
+	raw = tarBytes(t, []archiveEntry{
+		{name: "A.xml", body: []byte(sampleXML)},
+		{name: "B.xml", body: []byte(sampleXML)},
+	})
+	_, _, err = walkFile(t, "short.tar.gz", gzipBytes(t, raw[:1024+100]))
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Walk err = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestWalkCorruptZip(t *testing.T) {
+	raw := zipBytes(t, []archiveEntry{{name: "A.xml", body: []byte(sampleXML)}})
+	_, _, err := walkFile(t, "bundle.zip", raw[:len(raw)-30]) // central directory 손상
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Walk err = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestWalkMissingFile(t *testing.T) {
+	_, err := Walk(filepath.Join(t.TempDir(), "missing.xml"), func(string, io.Reader) error { return nil })
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Walk err = %v, want ErrNotExist", err)
+	}
+	if errors.Is(err, ErrCorrupt) || errors.Is(err, ErrUnsupported) {
+		t.Errorf("open failure classified as %v", err)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	tarHead := make([]byte, 512)
+	copy(tarHead[257:], "ustar")
+	cases := []struct {
+		name string
+		head []byte
+		want kind
+	}{
+		{"a.xml", []byte(sampleXML), kindXML},
+		{"a.bin", []byte{0x1f, 0x8b, 0x08}, kindGzip},
+		{"a.xml", []byte("PK\x03\x04rest"), kindZip},
+		{"a.gz", tarHead, kindTar},
+		{"a.zip", nil, kindZip},
+		{"A.TAR", []byte("x"), kindTar},
+		{"a.gz", []byte("<x/>"), kindXML},
+	}
+	for _, tc := range cases {
+		br := bufio.NewReader(bytes.NewReader(tc.head))
+		if got := sniff(br, tc.name); got != tc.want {
+			t.Errorf("sniff(%s, %q) = %d, want %d", tc.name, tc.head, got, tc.want)
+		}
+		// 판별 후에도 내용은 그대로 읽혀야 함
+		if b, _ := io.ReadAll(br); !bytes.Equal(b, tc.head) && len(tc.head) > 0 {
+			t.Errorf("sniff consumed bytes of %s", tc.name)
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for name, want := range map[string]bool{
+		"a.xml": true, "A.XML.GZ": true, "a.zip": true, "a.tar.gz": true, "a.tgz": true,
+		"a.txt": false, "a.tar": false, "a.xml.tmp": false,
+	} {
+		if got := Supported(name); got != want {
+			t.Errorf("Supported(%s) = %t, want %t", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package ledger
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const schema = `
+	CREATE TABLE IF NOT EXISTS processed_file (
+		hash         TEXT PRIMARY KEY,
+		path         TEXT NOT NULL,
+		size         INTEGER NOT NULL,
+		end_time     TEXT NOT NULL DEFAULT '',
+		status       TEXT NOT NULL,
+		doc_count    INTEGER NOT NULL DEFAULT 0,
+		failed_count INTEGER NOT NULL DEFAULT 0,
+		error        TEXT NOT NULL DEFAULT '',
+		started_at   TEXT NOT NULL,
+		updated_at   TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS processed_file_path ON processed_file(path);
+`
+
+// 처리 상태
+const (
+	StatusProcessing = "processing" // 파싱 시작, ES 응답 대기 중 (재시작 시 다시 처리)
+	StatusIndexed    = "indexed"    // 모든 문서가 ES 에서 성공 응답
+	StatusFailed     = "failed"     // 파싱 오류 또는 일부 문서 색인 실패
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// Entry: 처리 파일 기록 한 건
+type Entry struct {
+	Hash        string
+	Path        string
+	Size        int64
+	EndTime     string
+	Status      string
+	DocCount    int
+	FailedCount int
+	Error       string
+	StartedAt   string
+	UpdatedAt   string
+}
+
+// Ledger: 처리한 파일을 내용 해시 기준으로 기록하는 SQLite 원장.
+// 같은 내용의 파일이 다른 이름으로 다시 들어와도 중복 색인하지 않음.
+type Ledger struct {
+	db *sql.DB
+}
+
+// Open: 원장 DB 오픈 및 테이블 생성
+func Open(path string) (*Ledger, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("open ledger: %w", err)
+	}
+	// 쓰기 잠금 경합 방지
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create ledger schema: %w", err)
+	}
+	return &Ledger{db: db}, nil
+}
+
+func (l *Ledger) Close() error {
+	return l.db.Close()
+}
+
+// Fingerprint: 파일 크기와 SHA-256 해시
+func Fingerprint(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Lookup: 해시로 기록 조회. 없으면 (nil, nil).
+func (l *Ledger) Lookup(hash string) (*Entry, error) {
+	var e Entry
+	err := l.db.QueryRow(`
+		SELECT hash, path, size, end_time, status, doc_count, failed_count, error, started_at, updated_at
+		FROM processed_file WHERE hash = ?`, hash).
+		Scan(&e.Hash, &e.Path, &e.Size, &e.EndTime, &e.Status, &e.DocCount, &e.FailedCount, &e.Error, &e.StartedAt, &e.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup %s: %w", hash, err)
+	}
+	return &e, nil
+}
+
+// Begin: 처리 시작 기록 (이전 기록이 있으면 덮어씀)
+func (l *Ledger) Begin(hash, path string, size int64) error {
+	now := time.Now().Format(timeLayout)
+	_, err := l.db.Exec(`
+		INSERT INTO processed_file (hash, path, size, status, started_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(hash) DO UPDATE SET
+			path = excluded.path, size = excluded.size, end_time = '', status = excluded.status,
+			doc_count = 0, failed_count = 0, error = '', started_at = excluded.started_at, updated_at = excluded.updated_at`,
+		hash, path, size, StatusProcessing, now, now)
+	if err != nil {
+		return fmt.Errorf("begin %s: %w", path, err)
+	}
+	return nil
+}
+
+// Complete: ES 응답까지 끝난 결과 기록. failed 가 0 이고 cause 가 nil 이면 indexed, 아니면 failed.
+func (l *Ledger) Complete(hash, endTime string, docs, failed int, cause error) error {
+	status, msg := StatusIndexed, ""
+	if failed > 0 || cause != nil {
+		status = StatusFailed
+	}
+	if cause != nil {
+		msg = cause.Error()
+	}
+	_, err := l.db.Exec(`
+		UPDATE processed_file
+		SET end_time = ?, status = ?, doc_count = ?, failed_count = ?, error = ?, updated_at = ?
+		WHERE hash = ?`,
+		endTime, status, docs, failed, msg, time.Now().Format(timeLayout), hash)
+	if err != nil {
+		return fmt.Errorf("complete %s: %w", hash, err)
+	}
+	return nil
+}
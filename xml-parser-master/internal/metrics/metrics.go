@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"same-parser/internal/store"
+)
+
+const namespace = "lsm"
+
+// 파이프라인 지표 (기본 레지스트리에 등록, /metrics 로 노출)
+var (
+	// FsEvents: fsnotify 이벤트 수 (op 별)
+	FsEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fsnotify_events_total",
+		Help:      "fsnotify events seen, by op.",
+	}, []string{"op"})
+
+	// FilesQueued: jobChan 에 넣은 파일 수 (감시, 백필, 재시도 포함)
+	FilesQueued = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "files_queued_total",
+		Help:      "Files put on the job queue (watch, backfill and retry).",
+	})
+
+	// FilesInFlight: 처리 시작 후 출력 응답을 기다리는 중인 파일 수
+	FilesInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "files_in_flight",
+		Help:      "Files being parsed or waiting for output acknowledgements.",
+	})
+
+	// FilesProcessed: 처리 결과별 파일 수 (succeeded, failed, retried, skipped)
+	FilesProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "files_processed_total",
+		Help:      "Files by outcome: succeeded, failed, retried or skipped.",
+	}, []string{"result"})
+
+	// StableTimeouts: waitStable 안정화 타임아웃 수
+	StableTimeouts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stabilization_timeouts_total",
+		Help:      "Files whose size did not settle before the stabilization timeout.",
+	})
+
+	// ParseDuration: ProcessXML 소요 시간
+	ParseDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "parse_duration_seconds",
+		Help:      "Time spent parsing one input file.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14), // 10ms ~ 82s
+	})
+
+	// DocsEmitted: 생성한 문서 수 (montype 별)
+	DocsEmitted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "docs_emitted_total",
+		Help:      "Documents emitted by the parser, by montype.",
+	}, []string{"montype"})
+
+	// RuParamMisses: ru_mapping 에 없는 ru_param 수 (파일마다 중복 제거 후 합산)
+	RuParamMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ru_param_misses_total",
+		Help:      "ru_param values with no ru_mapping row, counted once per file.",
+	})
+)
+
+// Handler: /metrics 핸들러
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// QueueDepth: 채널 적체 gauge 등록 (lsm_queue_depth{queue=name}, lsm_queue_capacity{queue=name})
+func QueueDepth(name string, depth, capacity func() int) {
+	labels := prometheus.Labels{"queue": name}
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_depth",
+		Help:        "Items buffered in an internal channel.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(depth()) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_capacity",
+		Help:        "Buffer size of an internal channel.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(capacity()) })
+}
+
+// RegisterBulkIndexer: ES 벌크 인덱서 누적 통계 등록 (lsm_es_bulk_documents_total{state=...})
+func RegisterBulkIndexer(indexer esutil.BulkIndexer) {
+	stat := func(state string, get func(esutil.BulkIndexerStats) uint64) {
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "es_bulk_documents_total",
+			Help:        "BulkIndexer document counters.",
+			ConstLabels: prometheus.Labels{"state": state},
+		}, func() float64 { return float64(get(indexer.Stats())) })
+	}
+	stat("added", func(s esutil.BulkIndexerStats) uint64 { return s.NumAdded })
+	stat("flushed", func(s esutil.BulkIndexerStats) uint64 { return s.NumFlushed })
+	stat("failed", func(s esutil.BulkIndexerStats) uint64 { return s.NumFailed })
+	stat("indexed", func(s esutil.BulkIndexerStats) uint64 { return s.NumIndexed })
+	stat("created", func(s esutil.BulkIndexerStats) uint64 { return s.NumCreated })
+	stat("updated", func(s esutil.BulkIndexerStats) uint64 { return s.NumUpdated })
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "es_bulk_requests_total",
+		Help:      "Bulk requests sent by the BulkIndexer.",
+	}, func() float64 { return float64(indexer.Stats().NumRequests) })
+}
+
+// RegisterStore: ru_mapping 적재 현황 등록
+func RegisterStore(s *store.Store) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ru_mapping_rows",
+		Help:      "Rows loaded from ru_mapping on the last reload.",
+	}, func() float64 { return float64(s.Stats().Rows) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ru_mapping_keys",
+		Help:      "Distinct ru_param keys loaded on the last reload.",
+	}, func() float64 { return float64(s.Stats().Keys) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ru_mapping_last_reload_timestamp_seconds",
+		Help:      "Unix time of the last successful ru_mapping reload.",
+	}, func() float64 {
+		if t := s.Stats().LoadedAt; !t.IsZero() {
+			return float64(t.UnixNano()) / 1e9
+		}
+		return 0
+	})
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ru_mapping_reload_duration_seconds",
+		Help:      "Time taken by the last successful ru_mapping reload.",
+	}, func() float64 { return s.Stats().Duration.Seconds() })
+}
@@ -22,6 +22,30 @@ type ElasticDocument struct {
 	Timestamp   *string `json:"@timestamp"`
 	EquipID     *string `json:"equip_id"`
 	CollectDate *string `json:"collectDate"`
+	Generation  *string `json:"generation"` // LTE | NR
+	SourceFile  *string `json:"-"`          // 문서를 만든 원본 파일 경로 (묶음이면 묶음 파일). 응답 집계용으로 출력 문서에는 싣지 않음
+
+	Attrs    map[string]string `json:"attrs,omitempty"`    // ru_mapping 추가 컬럼 (mapping.attributes.columns)
+	Location *GeoPoint         `json:"location,omitempty"` // ru_mapping 위경도 (인덱스 템플릿에서 geo_point 로 매핑)
+	Topology *Topology         `json:"topology,omitempty"` // measObjLdn 에서 읽은 RU 위치 (매핑이 없어도 채움)
+
+	RetrySink string `json:"retry_sink,omitempty"` // 스풀에 되돌린 문서를 다시 보낼 출력 대상 이름 (출력 시 비움)
+}
+
+// Topology: measObjLdn 을 나눈 RU 위치 항목 (예: /UMP00/BID1/RuPort2/Cascade0, /UMP00/cNum3).
+// 규칙(topology)에 없거나 measObjLdn 에 없는 항목은 생략.
+type Topology struct {
+	BoardID   *int `json:"board_id,omitempty"`
+	RUPort    *int `json:"ru_port,omitempty"`
+	CascadeID *int `json:"cascade_id,omitempty"`
+	CellNum   *int `json:"cell_num,omitempty"`
+	Carrier   *int `json:"carrier,omitempty"`
+}
+
+// GeoPoint: Elasticsearch geo_point 객체 형식
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
 }
 
 type RuMapping struct {
@@ -33,6 +57,9 @@ type RuMapping struct {
 	RU_NAME  *string
 	CELL_ID  *string
 	CELL_NUM *string
+
+	Attrs    map[string]string `json:",omitempty"` // 추가 컬럼 → 값
+	Location *GeoPoint         `json:",omitempty"`
 }
 
 type RuMappingDAO struct {
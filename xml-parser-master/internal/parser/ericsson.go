@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"io"
+	"strings"
+)
+
+// NR 은 기본 규칙(defaults/ericsson_nr.yml)이 없어 등록하지 않음
+func init() {
+	Register("ERICSSON", "LTE", ericssonParser{})
+}
+
+// ericssonParser: Ericsson 3GPP XML (32.435 measCollecFile, measType p / r p 형식)
+type ericssonParser struct{}
+
+func (ericssonParser) Parse(r io.Reader, want func(string) bool) (*MeasCollec, error) {
+	return parseMeasCollecFile(r, want, func(attrs map[string]string) string {
+		// localDn 예: SubNetwork=ONRM_ROOT,MeContext=ENB123,ManagedElement=1
+		if me := rdnValue(attrs["localDn"], "MeContext"); me != "" {
+			return me
+		}
+		if attrs["userLabel"] != "" {
+			return attrs["userLabel"]
+		}
+		return attrs["localDn"]
+	})
+}
+
+// RuParam: measObjLdn 마지막 RDN 값
+// (예: ManagedElement=1,ENodeBFunction=1,EUtranCellFDD=ABC123 → ABC123, ericsson_*.sql 의 CELLFDDID/FDD_ID)
+func (ericssonParser) RuParam(_, objLdn string) string {
+	rdns := strings.Split(objLdn, ",")
+	last := rdns[len(rdns)-1]
+	if i := strings.IndexByte(last, '='); i >= 0 {
+		return last[i+1:]
+	}
+	return last
+}
+
+// rdnValue: "A=1,B=2" 형식 DN 에서 type 에 해당하는 값
+func rdnValue(dn, typ string) string {
+	for _, rdn := range strings.Split(dn, ",") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(rdn), "="); ok && k == typ {
+			return v
+		}
+	}
+	return ""
+}
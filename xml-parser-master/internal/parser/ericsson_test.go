@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestProcessXMLEricssonLTE: 32.435 measType p / r p 형식을 기본 Ericsson LTE 규칙으로 처리.
+// - PmGroup=EUtranCellFDD 하나를 montype 별로 나눠 씀 (MAC/ENDC 카운터는 파일에 없으므로 문서 없음)
+// - suspect measValue(CELL2)와 규칙에 없는 measInfo/measType 은 제외
+// - equip_id 는 localDn 의 MeContext, ru_param 은 measObjLdn 마지막 RDN 값
+func TestProcessXMLEricssonLTE(t *testing.T) {
+	got, docs, res := processFixture(t, "ERICSSON", "LTE", "testdata/ericsson_lte.xml", 15)
+
+	checkDocs(t, got, map[string]interface{}{
+		"MAXUE CELL1 UEMax":     25,
+		"PRB CELL1 PRBDL":       30.0,
+		"PRB CELL1 PRBUL":       24.0,
+		"RRC CELL1 RRCATTEMPT":  100,
+		"RRC CELL1 RRCSUCCRATE": 99.0,
+	})
+	for _, doc := range docs {
+		if *doc.EquipID != "ENB123" || *doc.MeasDate != "202405011015" {
+			t.Errorf("%s %s: equip_id=%s measdate=%s", *doc.MontypeName, doc.Data.Field, *doc.EquipID, *doc.MeasDate)
+		}
+	}
+	if res.EndTime != "2024-05-01T10:15:00+09:00" || res.ManagedElement != "ENB123" {
+		t.Errorf("result = endTime %q me %q", res.EndTime, res.ManagedElement)
+	}
+}
+
+// TestEricssonManagedElement: equip_id 는 MeContext → userLabel → localDn 순으로 선택
+func TestEricssonManagedElement(t *testing.T) {
+	cases := []struct {
+		attrs string
+		want  string
+	}{
+		{`localDn="SubNetwork=ONRM_ROOT,MeContext=ENB123,ManagedElement=1" userLabel="LABEL"`, "ENB123"},
+		{`localDn="SubNetwork=ONRM_ROOT, MeContext=ENB9 ,ManagedElement=1"`, "ENB9"},
+		{`localDn="ManagedElement=1" userLabel="LABEL"`, "LABEL"},
+		{`localDn="ManagedElement=1"`, "ManagedElement=1"},
+	}
+	for _, tc := range cases {
+		xml := `<measCollecFile><measData><managedElement ` + tc.attrs + `/></measData></measCollecFile>`
+		mc, err := ericssonParser{}.Parse(strings.NewReader(xml), func(string) bool { return true })
+		if err != nil {
+			t.Fatalf("%s: %v", tc.attrs, err)
+		}
+		if mc.ManagedElement != tc.want {
+			t.Errorf("%s: ManagedElement = %q, want %q", tc.attrs, mc.ManagedElement, tc.want)
+		}
+	}
+}
+
+func TestEricssonRuParam(t *testing.T) {
+	cases := []struct {
+		ldn  string
+		want string
+	}{
+		{"ManagedElement=1,ENodeBFunction=1,EUtranCellFDD=ABC123", "ABC123"},
+		{"EUtranCellFDD=ABC123", "ABC123"},
+		{"ManagedElement=1,ENodeBFunction=1,EUtranCellFDD=", ""},
+		{"ABC123", "ABC123"},
+	}
+	for _, tc := range cases {
+		if got := (ericssonParser{}).RuParam("ENB123", tc.ldn); got != tc.want {
+			t.Errorf("RuParam(%q) = %q, want %q", tc.ldn, got, tc.want)
+		}
+	}
+}
+
+func TestRdnValue(t *testing.T) {
+	cases := []struct {
+		dn, typ string
+		want    string
+	}{
+		{"SubNetwork=ONRM_ROOT,MeContext=ENB123,ManagedElement=1", "MeContext", "ENB123"},
+		{"SubNetwork=ONRM_ROOT, MeContext=ENB123 ,ManagedElement=1", "MeContext", "ENB123"},
+		{"SubNetwork=ONRM_ROOT,MeContext=ENB123,ManagedElement=1", "ManagedElement", "1"},
+		{"SubNetwork=ONRM_ROOT,ManagedElement=1", "MeContext", ""},
+		{"MeContext", "MeContext", ""},
+		{"", "MeContext", ""},
+	}
+	for _, tc := range cases {
+		if got := rdnValue(tc.dn, tc.typ); got != tc.want {
+			t.Errorf("rdnValue(%q, %q) = %q, want %q", tc.dn, tc.typ, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	xmlparser "github.com/tamerh/xml-stream-parser"
+)
+
+// MeasInfo: 인코딩(Samsung LSM / 3GPP TS 32.435)과 무관하게 디코딩한 measInfo 블록 하나
+type MeasInfo struct {
+	MeasInfoID string        `json:"measInfoId"`
+	GranPeriod time.Duration `json:"granPeriod"` // granPeriod duration, 파일에 없으면 0
+	RepPeriod  time.Duration `json:"repPeriod"`  // repPeriod duration, 파일에 없으면 0
+	EndTime    string        `json:"endTime"`    // granPeriod endTime, 파일에 없으면 ""
+	Values     []MeasValue   `json:"values"`
+}
+
+// MeasValue: measObjLdn 하나의 measType → 결과 값
+type MeasValue struct {
+	ObjLdn  string            `json:"measObjLdn"`
+	Results map[string]string `json:"results"`
+	Suspect bool              `json:"suspect"`
+}
+
+// decodeMeasInfo: measInfo 노드를 MeasInfo로 변환.
+// - LSM: <measTypes>a b c</measTypes>, <measResults>1 2 3</measResults> (공백 구분 문자열)
+// - 32.435: <measType p="1">a</measType>, <r p="1">1</r> (p 인덱스로 짝지음)
+// 두 형식은 measInfo/measValue 단위로 각각 판별하므로 한 파일에 섞여 있어도 처리 가능.
+func decodeMeasInfo(node *xmlparser.XMLElement) (MeasInfo, error) {
+	mi := MeasInfo{MeasInfoID: node.Attrs["measInfoId"]}
+
+	if gp := node.Childs["granPeriod"]; len(gp) > 0 {
+		d, err := parseISODuration(gp[0].Attrs["duration"])
+		if err != nil {
+			return mi, fmt.Errorf("measInfo %s granPeriod: %w", mi.MeasInfoID, err)
+		}
+		mi.GranPeriod = d
+		mi.EndTime = gp[0].Attrs["endTime"]
+	}
+	if rp := node.Childs["repPeriod"]; len(rp) > 0 {
+		d, err := parseISODuration(rp[0].Attrs["duration"])
+		if err != nil {
+			return mi, fmt.Errorf("measInfo %s repPeriod: %w", mi.MeasInfoID, err)
+		}
+		mi.RepPeriod = d
+	}
+
+	// measType 목록: LSM 은 순서 그대로, 32.435 는 p 인덱스 → 이름
+	var lsmTypes []string
+	if mt := node.Childs["measTypes"]; len(mt) > 0 {
+		lsmTypes = strings.Fields(mt[0].InnerText)
+	}
+	pTypes := make(map[string]string, len(node.Childs["measType"]))
+	for _, t := range node.Childs["measType"] {
+		pTypes[t.Attrs["p"]] = strings.TrimSpace(t.InnerText)
+	}
+
+	mi.Values = make([]MeasValue, 0, len(node.Childs["measValue"]))
+	for _, mv := range node.Childs["measValue"] {
+		v := MeasValue{
+			ObjLdn:  mv.Attrs["measObjLdn"],
+			Results: make(map[string]string),
+		}
+		if s := mv.Childs["suspect"]; len(s) > 0 {
+			v.Suspect = strings.EqualFold(strings.TrimSpace(s[0].InnerText), "true")
+		}
+
+		if res := mv.Childs["measResults"]; len(res) > 0 {
+			values := strings.Fields(res[0].InnerText)
+			for i, t := range lsmTypes {
+				var val string
+				if i < len(values) {
+					val = values[i]
+				}
+				v.Results[t] = val
+			}
+		}
+		if rs := mv.Childs["r"]; len(rs) > 0 {
+			if len(pTypes) == 0 && len(lsmTypes) > 0 {
+				// measTypes 문자열 + r 요소 조합: p 는 1부터 시작하는 순번
+				for i, t := range lsmTypes {
+					pTypes[strconv.Itoa(i+1)] = t
+				}
+			}
+			for _, r := range rs {
+				if t, ok := pTypes[r.Attrs["p"]]; ok {
+					v.Results[t] = strings.TrimSpace(r.InnerText)
+				}
+			}
+		}
+		mi.Values = append(mi.Values, v)
+	}
+	return mi, nil
+}
+
+var isoDurationRe = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseISODuration: ISO 8601 기간(PT900S, PT15M, PT1H, P1D 등) 파싱
+func parseISODuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	m := isoDurationRe.FindStringSubmatch(s)
+	if m == nil || s == "P" || s == "PT" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var d time.Duration
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute}
+	for i, u := range units {
+		if m[i+1] != "" {
+			n, _ := strconv.Atoi(m[i+1])
+			d += time.Duration(n) * u
+		}
+	}
+	if m[4] != "" {
+		f, _ := strconv.ParseFloat(m[4], 64)
+		d += time.Duration(f * float64(time.Second))
+	}
+	return d, nil
+}
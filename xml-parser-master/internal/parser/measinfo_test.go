@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	xmlparser "github.com/tamerh/xml-stream-parser"
+)
+
+func TestParseISODuration(t *testing.T) {
+	cases := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{"PT900S", 15 * time.Minute, false},
+		{"PT15M", 15 * time.Minute, false},
+		{"PT1H", time.Hour, false},
+		{"P1D", 24 * time.Hour, false},
+		{"P1DT1H30M", 25*time.Hour + 30*time.Minute, false},
+		{"PT0.5S", 500 * time.Millisecond, false},
+		{" PT60S ", time.Minute, false},
+		{"", 0, false},
+		{"P", 0, true},
+		{"PT", 0, true},
+		{"900", 0, true},
+		{"PT15X", 0, true},
+		{"P1H", 0, true},
+	}
+	for _, tc := range cases {
+		got, err := parseISODuration(tc.in)
+		if (err != nil) != tc.err || got != tc.want {
+			t.Errorf("parseISODuration(%q) = %s, %v; want %s, err %t", tc.in, got, err, tc.want, tc.err)
+		}
+	}
+}
+
+// decodeXML: measInfo 요소 하나를 스트림 파서로 읽어 decodeMeasInfo 실행
+func decodeXML(t *testing.T, xml string) (MeasInfo, error) {
+	t.Helper()
+	parser := xmlparser.NewXMLParser(bufio.NewReader(strings.NewReader(xml)), "measInfo")
+	for node := range parser.Stream() {
+		if node.Err != nil {
+			t.Fatal(node.Err)
+		}
+		return decodeMeasInfo(node)
+	}
+	t.Fatal("no measInfo")
+	return MeasInfo{}, nil
+}
+
+func TestDecodeMeasInfo(t *testing.T) {
+	cases := []struct {
+		name string
+		xml  string
+		want MeasInfo
+	}{
+		{
+			name: "lsm strings",
+			xml: `<measInfo measInfoId="A">
+  <measTypes>a b c</measTypes>
+  <measValue measObjLdn="/X"><measResults>1 2</measResults></measValue>
+</measInfo>`,
+			want: MeasInfo{MeasInfoID: "A", Values: []MeasValue{
+				{ObjLdn: "/X", Results: map[string]string{"a": "1", "b": "2", "c": ""}},
+			}},
+		},
+		{
+			name: "32.435 p index",
+			xml: `<measInfo measInfoId="B">
+  <granPeriod duration="PT900S" endTime="2024-05-01T10:15:00Z"/>
+  <repPeriod duration="PT1H"/>
+  <measType p="1">a</measType>
+  <measType p="3"> c </measType>
+  <measValue measObjLdn="/X">
+    <r p="3">30</r>
+    <r p="1"> 10 </r>
+    <r p="2">20</r>
+  </measValue>
+  <measValue measObjLdn="/Y">
+    <r p="1">11</r>
+    <suspect>TRUE</suspect>
+  </measValue>
+</measInfo>`,
+			want: MeasInfo{MeasInfoID: "B", GranPeriod: 15 * time.Minute, RepPeriod: time.Hour, EndTime: "2024-05-01T10:15:00Z", Values: []MeasValue{
+				{ObjLdn: "/X", Results: map[string]string{"a": "10", "c": "30"}},
+				{ObjLdn: "/Y", Results: map[string]string{"a": "11"}, Suspect: true},
+			}},
+		},
+		{
+			name: "measTypes string with r elements",
+			xml: `<measInfo measInfoId="C">
+  <measTypes>a b</measTypes>
+  <measValue measObjLdn="/X"><r p="2">2</r><r p="1">1</r><suspect>false</suspect></measValue>
+</measInfo>`,
+			want: MeasInfo{MeasInfoID: "C", Values: []MeasValue{
+				{ObjLdn: "/X", Results: map[string]string{"a": "1", "b": "2"}},
+			}},
+		},
+		{
+			name: "no values",
+			xml:  `<measInfo measInfoId="D"><measType p="1">a</measType></measInfo>`,
+			want: MeasInfo{MeasInfoID: "D", Values: []MeasValue{}},
+		},
+	}
+	for _, tc := range cases {
+		got, err := decodeXML(t, tc.xml)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeMeasInfoBadDuration(t *testing.T) {
+	for _, xml := range []string{
+		`<measInfo measInfoId="A"><granPeriod duration="15min" endTime="2024-05-01T10:15:00Z"/></measInfo>`,
+		`<measInfo measInfoId="A"><repPeriod duration="PT"/></measInfo>`,
+	} {
+		if _, err := decodeXML(t, xml); err == nil || !strings.Contains(err.Error(), "measInfo A") {
+			t.Errorf("%s: err = %v", xml, err)
+		}
+	}
+}
+
+// TestParseMeasCollecFileErrors: 디코딩 오류 measInfo 는 건너뛰고 계속, XML 오류는 그때까지 읽은 결과와 함께 반환
+func TestParseMeasCollecFileErrors(t *testing.T) {
+	xml := `<measCollecFile><measData>
+  <managedElement localDn="DU1"/>
+  <measInfo measInfoId="A"><granPeriod duration="bad"/></measInfo>
+  <measInfo measInfoId="B"><measTypes>x</measTypes><measValue measObjLdn="/X"><measResults>1</measResults></measValue></measInfo>
+  <measInfo measInfoId="C"><measTypes>y</measTypes><measValue measObjLdn="/Y"><measResults>2`
+	mc, err := samsungParser{}.Parse(strings.NewReader(xml), func(string) bool { return true })
+	if err == nil {
+		t.Error("truncated XML: no error")
+	}
+	if len(mc.Errors) != 1 || !strings.Contains(mc.Errors[0].Error(), "measInfo A") {
+		t.Errorf("decode errors = %v", mc.Errors)
+	}
+	if mc.ManagedElement != "DU1" || len(mc.MeasInfos) != 1 || mc.MeasInfos[0].MeasInfoID != "B" {
+		t.Errorf("result = %+v", mc)
+	}
+}
+
+// TestProcessXMLSamsung32435: 32.435 요소 형식 Samsung 파일을 LSM 과 같은 규칙으로 처리.
+// - r p 순서와 무관하게 measType 과 짝지음, suspect 값 제외
+// - granPeriod endTime 이 다른 measInfo 는 그 endTime 으로 문서 생성
+// - montype 적용 여부는 파일 granPeriod(RRC 는 PT3600S)가 아니라 logging.collection_period 로 판단
+func TestProcessXMLSamsung32435(t *testing.T) {
+	got, docs, res := processFixture(t, "SAMSUNG", "LTE", "testdata/samsung_lte_32435.xml", 15)
+	checkDocs(t, got, map[string]interface{}{
+		"PRB DU001/UMP00/BID1/RuPort0/Cascade0 PRBDL": 45.68,
+		"PRB DU001/UMP00/BID1/RuPort0/Cascade0 PRBUL": 12.3,
+		"RRC DU001/UMP00/cNum1 RRCATTEMPT":            150,
+		"RRC DU001/UMP00/cNum1 RRCSUCCRATE":           94.0,
+	})
+	for _, doc := range docs {
+		want := "202405011015"
+		if *doc.MontypeName == "RRC" {
+			want = "202405011100"
+		}
+		if *doc.MeasDate != want {
+			t.Errorf("%s %s: measdate = %s, want %s", *doc.MontypeName, doc.Data.Field, *doc.MeasDate, want)
+		}
+	}
+	if res.EndTime != "2024-05-01T10:15:00+09:00" {
+		t.Errorf("endTime = %q", res.EndTime)
+	}
+
+	// 설정 수집 주기가 60 이면 exclude_periods: [60] 인 montype 은 파일 granPeriod 와 무관하게 제외
+	got, _, _ = processFixture(t, "SAMSUNG", "LTE", "testdata/samsung_lte_32435.xml", 60)
+	checkDocs(t, got, map[string]interface{}{})
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	xmlparser "github.com/tamerh/xml-stream-parser"
+)
+
+// NR 은 기본 규칙(defaults/nokia_nr.yml)이 없어 등록하지 않음
+func init() {
+	Register("NOKIA", "LTE", nokiaParser{})
+}
+
+// nokiaParser: Nokia OMeS PM XML
+//
+//	<OMeS>
+//	  <PMSetup startTime="2025-01-01T10:00:00.000+09:00" interval="15">
+//	    <PMMOResult>
+//	      <MO dimension="network_element"><DN>PLMN-PLMN/MRBTS-100/LNBTS-100/LNCEL-1</DN></MO>
+//	      <PMTarget measurementType="LTE_Cell_Load"><M8001C6>12</M8001C6>...</PMTarget>
+//	    </PMMOResult>
+//	  </PMSetup>
+//	</OMeS>
+//
+// measurementType 을 measInfoId, DN 을 measObjLdn, PMTarget 하위 요소를 measType 으로 정규화.
+type nokiaParser struct{}
+
+func (nokiaParser) Parse(r io.Reader, want func(string) bool) (*MeasCollec, error) {
+	parser := xmlparser.NewXMLParser(bufio.NewReader(r), "PMSetup", "PMMOResult").ParseAttributesOnly("PMSetup")
+
+	mc := &MeasCollec{}
+	byKey := make(map[string]int) // measurementType|endTime → mc.MeasInfos 인덱스
+	var interval time.Duration
+	var endTime string
+
+	for node := range parser.Stream() {
+		if node.Err != nil {
+			return mc, node.Err
+		}
+
+		switch node.Name {
+		case "PMSetup":
+			start, err := time.Parse(time.RFC3339, node.Attrs["startTime"])
+			if err != nil {
+				mc.Errors = append(mc.Errors, fmt.Errorf("PMSetup startTime: %w", err))
+				endTime = ""
+				continue
+			}
+			minutes, err := strconv.Atoi(node.Attrs["interval"])
+			if err != nil {
+				mc.Errors = append(mc.Errors, fmt.Errorf("PMSetup interval: %w", err))
+				endTime = ""
+				continue
+			}
+			interval = time.Duration(minutes) * time.Minute
+			endTime = start.Add(interval).Format("2006-01-02T15:04:05.000-07:00")
+			if mc.EndTime == "" {
+				mc.EndTime = endTime
+			}
+		case "PMMOResult":
+			if endTime == "" {
+				continue
+			}
+			dn := nokiaDN(node)
+			if dn == "" {
+				continue
+			}
+			if mc.ManagedElement == "" {
+				mc.ManagedElement = nokiaSegment(dn, "MRBTS")
+			}
+			for _, target := range node.Childs["PMTarget"] {
+				id := target.Attrs["measurementType"]
+				if !want(id) {
+					continue
+				}
+				v := MeasValue{ObjLdn: dn, Results: make(map[string]string, len(target.Childs))}
+				for name, counters := range target.Childs {
+					if len(counters) > 0 {
+						v.Results[name] = strings.TrimSpace(counters[0].InnerText)
+					}
+				}
+				key := id + "|" + endTime
+				idx, ok := byKey[key]
+				if !ok {
+					idx = len(mc.MeasInfos)
+					byKey[key] = idx
+					mc.MeasInfos = append(mc.MeasInfos, MeasInfo{MeasInfoID: id, GranPeriod: interval, EndTime: endTime})
+				}
+				mc.MeasInfos[idx].Values = append(mc.MeasInfos[idx].Values, v)
+			}
+		}
+	}
+	return mc, nil
+}
+
+// RuParam: MRBTS ID + "/" + DN 마지막 객체 ID
+// (예: PLMN-PLMN/MRBTS-100/LNBTS-100/LNCEL-11 → 100/11, nokia_*.sql 의 ENBID/CELLFDDID, MRBTS/RMOD)
+func (nokiaParser) RuParam(_, objLdn string) string {
+	segs := strings.Split(objLdn, "/")
+	bts := nokiaSegment(objLdn, "MRBTS")
+	if bts == "" {
+		bts = nokiaSegment(objLdn, "LNBTS")
+	}
+	return nokiaID(bts) + "/" + nokiaID(segs[len(segs)-1])
+}
+
+// nokiaDN: PMMOResult 의 network_element MO DN (없으면 첫 MO DN)
+func nokiaDN(node *xmlparser.XMLElement) string {
+	var first string
+	for _, mo := range node.Childs["MO"] {
+		dns := mo.Childs["DN"]
+		if len(dns) == 0 {
+			continue
+		}
+		dn := strings.TrimSpace(dns[0].InnerText)
+		if mo.Attrs["dimension"] == "network_element" {
+			return dn
+		}
+		if first == "" {
+			first = dn
+		}
+	}
+	return first
+}
+
+// nokiaSegment: DN 에서 "<class>-<id>" 세그먼트 조회 (예: MRBTS → MRBTS-100)
+func nokiaSegment(dn, class string) string {
+	for _, seg := range strings.Split(dn, "/") {
+		if strings.HasPrefix(seg, class+"-") {
+			return seg
+		}
+	}
+	return ""
+}
+
+// nokiaID: "<class>-<id>" 세그먼트의 id 부분
+func nokiaID(seg string) string {
+	if i := strings.LastIndexByte(seg, '-'); i >= 0 {
+		return seg[i+1:]
+	}
+	return seg
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProcessXMLNokiaLTE: OMeS 파일을 기본 Nokia LTE 규칙으로 처리.
+// - PMTarget measurementType 이 measInfoId, 하위 요소가 measType
+// - LTE_UE_State 와 LTE_RRC 는 같은 DN 의 RRC 한 행으로 합쳐짐
+// - DN 은 network_element MO, ru_param 은 MRBTS ID + "/" + DN 마지막 객체 ID
+// - endTime 은 startTime + interval
+func TestProcessXMLNokiaLTE(t *testing.T) {
+	got, docs, res := processFixture(t, "NOKIA", "LTE", "testdata/nokia_lte.xml", 15)
+
+	checkDocs(t, got, map[string]interface{}{
+		"MAXUE 100/11 UEMax":     42,
+		"PRB 100/11 PRBDL":       50.0,
+		"PRB 100/11 PRBUL":       25.0,
+		"RRC 100/12 RRCATTEMPT":  100,
+		"RRC 100/12 RRCSUCCRATE": 95.0,
+	})
+	for _, doc := range docs {
+		if *doc.EquipID != "MRBTS-100" || *doc.MeasDate != "202405011015" {
+			t.Errorf("%s %s: equip_id=%s measdate=%s", *doc.MontypeName, doc.Data.Field, *doc.EquipID, *doc.MeasDate)
+		}
+	}
+	if res.EndTime != "2024-05-01T10:15:00.000+09:00" || res.ManagedElement != "MRBTS-100" {
+		t.Errorf("result = endTime %q me %q", res.EndTime, res.ManagedElement)
+	}
+}
+
+// TestNokiaParse: PMSetup 마다 endTime 을 계산하고, 시간 오류가 난 PMSetup 의 결과는 건너뜀
+func TestNokiaParse(t *testing.T) {
+	xml := `<OMeS>
+  <PMSetup startTime="2024-05-01T10:00:00.000+09:00" interval="15">
+    <PMMOResult>
+      <MO><DN>PLMN-PLMN/MRBTS-7/LNBTS-7/LNCEL-1/LNREL-2</DN></MO>
+      <PMTarget measurementType="LTE_Cell_Load"><M8001C224> 5 </M8001C224><M8001C6>9</M8001C6></PMTarget>
+      <PMTarget measurementType="Skipped"><M1>1</M1></PMTarget>
+    </PMMOResult>
+    <PMMOResult>
+      <MO dimension="network_element"><DN>PLMN-PLMN/MRBTS-7/LNBTS-7/LNCEL-2</DN></MO>
+      <PMTarget measurementType="LTE_Cell_Load"><M8001C224>6</M8001C224></PMTarget>
+    </PMMOResult>
+    <PMMOResult>
+      <PMTarget measurementType="LTE_Cell_Load"><M8001C224>99</M8001C224></PMTarget>
+    </PMMOResult>
+  </PMSetup>
+  <PMSetup startTime="2024-05-01T10:15:00.000+09:00" interval="60">
+    <PMMOResult>
+      <MO dimension="network_element"><DN>PLMN-PLMN/MRBTS-7/LNBTS-7/LNCEL-1</DN></MO>
+      <PMTarget measurementType="LTE_Cell_Load"><M8001C224>7</M8001C224></PMTarget>
+    </PMMOResult>
+  </PMSetup>
+  <PMSetup startTime="not a time" interval="15">
+    <PMMOResult>
+      <MO dimension="network_element"><DN>PLMN-PLMN/MRBTS-7/LNBTS-7/LNCEL-3</DN></MO>
+      <PMTarget measurementType="LTE_Cell_Load"><M8001C224>8</M8001C224></PMTarget>
+    </PMMOResult>
+  </PMSetup>
+  <PMSetup startTime="2024-05-01T10:00:00.000+09:00" interval="x">
+    <PMMOResult>
+      <MO dimension="network_element"><DN>PLMN-PLMN/MRBTS-7/LNBTS-7/LNCEL-4</DN></MO>
+      <PMTarget measurementType="LTE_Cell_Load"><M8001C224>9</M8001C224></PMTarget>
+    </PMMOResult>
+  </PMSetup>
+</OMeS>`
+	mc, err := nokiaParser{}.Parse(strings.NewReader(xml), func(id string) bool { return id != "Skipped" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mc.EndTime != "2024-05-01T10:15:00.000+09:00" || mc.ManagedElement != "MRBTS-7" {
+		t.Errorf("endTime %q me %q", mc.EndTime, mc.ManagedElement)
+	}
+	if len(mc.Errors) != 2 {
+		t.Errorf("errors = %v, want startTime and interval errors", mc.Errors)
+	}
+
+	// measurementType + endTime 별 measInfo
+	if len(mc.MeasInfos) != 2 {
+		t.Fatalf("measInfos = %+v", mc.MeasInfos)
+	}
+	first, second := mc.MeasInfos[0], mc.MeasInfos[1]
+	if first.MeasInfoID != "LTE_Cell_Load" || first.GranPeriod != 15*time.Minute || first.EndTime != "2024-05-01T10:15:00.000+09:00" {
+		t.Errorf("first = %+v", first)
+	}
+	if len(first.Values) != 2 ||
+		first.Values[0].ObjLdn != "PLMN-PLMN/MRBTS-7/LNBTS-7/LNCEL-1/LNREL-2" || first.Values[0].Results["M8001C224"] != "5" || first.Values[0].Results["M8001C6"] != "9" ||
+		first.Values[1].ObjLdn != "PLMN-PLMN/MRBTS-7/LNBTS-7/LNCEL-2" || first.Values[1].Results["M8001C224"] != "6" {
+		t.Errorf("first values = %+v", first.Values)
+	}
+	if second.GranPeriod != time.Hour || second.EndTime != "2024-05-01T11:15:00.000+09:00" ||
+		len(second.Values) != 1 || second.Values[0].Results["M8001C224"] != "7" {
+		t.Errorf("second = %+v", second)
+	}
+}
+
+func TestNokiaRuParam(t *testing.T) {
+	cases := []struct {
+		ldn  string
+		want string
+	}{
+		{"PLMN-PLMN/MRBTS-100/LNBTS-100/LNCEL-11", "100/11"},
+		{"PLMN-PLMN/MRBTS-100/EQM-1/APEQM-1/RMOD-3", "100/3"},
+		{"PLMN-PLMN/LNBTS-200/LNCEL-5", "200/5"},
+		{"PLMN-PLMN/MRBTS-100", "100/100"},
+		{"LNCEL-5", "/5"},
+	}
+	for _, tc := range cases {
+		if got := (nokiaParser{}).RuParam("MRBTS-100", tc.ldn); got != tc.want {
+			t.Errorf("RuParam(%q) = %q, want %q", tc.ldn, got, tc.want)
+		}
+	}
+}
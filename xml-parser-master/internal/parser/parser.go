@@ -1,303 +1,322 @@
 package parser
 
 import (
-	"bufio"
+	"errors"
+	"fmt"
 	"github.com/sirupsen/logrus"
-	"os"
+	"io"
 	"same-parser/internal/config"
+	"same-parser/internal/input"
 	"same-parser/internal/model"
+	"same-parser/internal/rules"
 	"same-parser/internal/store"
 	"strconv"
 	"time"
 
 	"strings"
-
-	xmlparser "github.com/tamerh/xml-stream-parser"
 )
 
 type MeasInfoData struct {
-	EndTime           string     `json:"endTime"`
-	ManagementElement string     `json:"ManagementElement"`
-	MeasResult        []MeasInfo `json:"measResult"`
+	EndTime           string       `json:"endTime"`
+	ManagementElement string       `json:"ManagementElement"`
+	Generation        string       `json:"generation"`
+	MeasResult        []MeasResult `json:"measResult"`
 }
 
-// MeasInfo: 수집 타입 이름과 key/value 값 맵의 슬라이스
-type MeasInfo struct {
+// MeasResult: 수집 타입 이름과 key/value 값 맵의 슬라이스
+type MeasResult struct {
 	MontypeName string              `json:"montypeName"`
+	EndTime     string              `json:"endTime"` // measInfo granPeriod endTime, 없으면 파일 endTime
 	Values      []map[string]string `json:"values"`
 }
 
-// ProcessXML: XML 파일을 스트리밍으로 읽어 각 measInfo를 처리하고, 최종 지표를 조합해 시간 포맷 변환 후 문서 생성 및 docChan으로 전송
-func ProcessXML(logger *logrus.Logger, cfg *config.Config, store *store.Store, filename string, docChan chan<- model.ElasticDocument) {
-	file, err := os.Open(filename)
-	if err != nil {
-		logger.Errorf("파일 열기 오류: %v", err)
-		return
-	}
-	defer file.Close()
-
+// ProcessXML: PM 파일(XML 또는 .gz/.zip/.tar.gz 묶음)을 열어 포함된 XML 마다 processStream 실행.
+// 압축은 디스크에 풀지 않고 스트리밍으로 해제하며, 처리 결과와 함께 첫 번째 실패를 *ParseError 로 반환.
+// 일부 멤버만 실패한 경우에도 성공한 멤버의 문서는 이미 docChan 으로 전송된 상태.
+// 문서의 SourceFile 은 filename 으로 지정되어 ES 응답을 파일 단위로 추적할 수 있음.
+func ProcessXML(logger *logrus.Logger, cfg *config.Config, rs *rules.Ruleset, vp Parser, store *store.Store, filename string, docChan chan<- model.ElasticDocument) (*ParseResult, error) {
 	start := time.Now()
+	res := newParseResult(filename)
 
-	parser := xmlparser.NewXMLParser(bufio.NewReader(file), "measInfo", "measCollec", "managedElement")
+	memberErrs, err := input.Walk(filename, func(name string, r io.Reader) error {
+		return processStream(logger, cfg, rs, vp, store, name, r, res, docChan)
+	})
+	for _, memberErr := range memberErrs {
+		res.XMLErrors = append(res.XMLErrors, memberErr)
+	}
+	res.finish(start)
 
-	var parsedResult MeasInfoData
-	var parsed []MeasInfo
+	if err != nil {
+		return res, &ParseError{Kind: walkErrorKind(err), File: filename, Err: err}
+	}
+	if len(memberErrs) > 0 {
+		first := memberErrs[0]
+		// 압축 손상으로 XML 이 끊긴 경우 XML/시간 오류보다 손상으로 분류
+		kind := walkErrorKind(first.Err)
+		var pe *ParseError
+		if kind == ErrOpen && errors.As(first, &pe) {
+			return res, &ParseError{Kind: pe.Kind, File: first.Member, Err: pe.Err}
+		}
+		return res, &ParseError{Kind: kind, File: first.Member, Err: first.Err}
+	}
+	return res, nil
+}
 
-	rrcMap := make(map[string]map[string]float64) // RRC 합산용 버퍼
+// walkErrorKind: input.Walk 오류 → ErrorKind (묶음 구조 문제가 아니면 파일 열기/읽기 실패)
+func walkErrorKind(err error) ErrorKind {
+	switch {
+	case errors.Is(err, input.ErrUnsupported):
+		return ErrUnsupported
+	case errors.Is(err, input.ErrCorrupt):
+		return ErrCorrupt
+	}
+	return ErrOpen
+}
 
-	for node := range parser.Stream() {
-		if node.Err != nil {
-			logger.Errorf("XML 파싱 오류: %v", node.Err)
-			continue
-		}
+// processStream: 벤더 파서(vp)로 XML 스트림 하나를 읽어 각 measInfo를 규칙에 따라 montype별로 모으고, 시간 포맷 변환 후 지표별 문서 생성 및 docChan으로 전송
+func processStream(logger *logrus.Logger, cfg *config.Config, rs *rules.Ruleset, vp Parser, store *store.Store, name string, r io.Reader, res *ParseResult, docChan chan<- model.ElasticDocument) error {
+	start := time.Now()
+
+	// XML 오류로 중단되어도 그때까지 읽은 measInfo 는 처리하고, 오류는 마지막에 반환
+	mc, parseErr := vp.Parse(r, func(id string) bool { return len(rs.MeasInfo(id)) > 0 })
+	for _, decodeErr := range mc.Errors {
+		logger.Errorf("measInfo 디코딩 오류: %s %v", name, decodeErr)
+		res.XMLErrors = append(res.XMLErrors, fmt.Errorf("%s: %w", name, decodeErr))
+	}
 
-		switch node.Name {
-		case "measCollec":
-			if t, ok := node.Attrs["endTime"]; ok {
-				parsedResult.EndTime = t
+	parsedResult := MeasInfoData{EndTime: mc.EndTime, ManagementElement: mc.ManagedElement, Generation: cfg.Generation()}
+	accs := make(map[accKey]*montypeAcc) // montype/endTime별 measValue 누적 버퍼
+	var accOrder []accKey
+	suspects := 0
+
+	for i := range mc.MeasInfos {
+		mi := &mc.MeasInfos[i]
+		for _, rule := range rs.MeasInfo(mi.MeasInfoID) {
+			// 적용 여부는 설정 수집 주기(logging.collection_period)로 판단 (파일의 granPeriod 는 쓰지 않음)
+			mt, _ := rs.Montype(rule.Montype)
+			if !mt.AppliesTo(cfg.Logging.CollectionPeriod) {
+				continue
 			}
-		case "managedElement":
-			if t, ok := node.Attrs["localDn"]; ok {
-				parsedResult.ManagementElement = t // DU
+			key := accKey{montype: rule.Montype, endTime: mi.EndTime}
+			acc, ok := accs[key]
+			if !ok {
+				acc = newMontypeAcc()
+				accs[key] = acc
+				accOrder = append(accOrder, key)
 			}
-		case "measInfo":
-			switch node.Attrs["measInfoId"] {
-			case "Resource Management/RU Power Consumption":
-				typeText := firstOrEmpty(node.Childs["measTypes"])
-				res := make([]map[string]string, 0, len(node.Childs["measValue"]))
-				for _, mv := range node.Childs["measValue"] {
-					objLdn := mv.Attrs["measObjLdn"]
-					resText := firstOrEmpty(mv.Childs["measResults"])
-					m := zipResults(typeText, resText)
-					m["RU"] = objLdn
-					res = append(res, m)
-				}
-				parsed = append(parsed, MeasInfo{MontypeName: "POWER", Values: res})
-
-			case "RRC/RRC Connection Number":
-				typeText := firstOrEmpty(node.Childs["measTypes"])
-				res := make([]map[string]string, 0, len(node.Childs["measValue"]))
-				for _, mv := range node.Childs["measValue"] {
-					objLdn := mv.Attrs["measObjLdn"]
-					resText := firstOrEmpty(mv.Childs["measResults"])
-					m := zipResults(typeText, resText)
-					m["RU"] = objLdn
-					res = append(res, m)
-				}
-				parsed = append(parsed, MeasInfo{MontypeName: "MAXUE", Values: res})
-
-			case "Packet Statistics/Air MAC Packet":
-				typeText := firstOrEmpty(node.Childs["measTypes"])
-				res := make([]map[string]string, 0, len(node.Childs["measValue"]))
-				for _, mv := range node.Childs["measValue"] {
-					objLdn := mv.Attrs["measObjLdn"]
-					resText := firstOrEmpty(mv.Childs["measResults"])
-					m := zipResults(typeText, resText)
-					m["RU"] = objLdn
-					res = append(res, m)
-				}
-				parsed = append(parsed, MeasInfo{MontypeName: "MAC", Values: res})
-
-			case "E-UTRA-NR Dual Connectivity/EN-DC Addition Information":
-				typeText := firstOrEmpty(node.Childs["measTypes"])
-				res := make([]map[string]string, 0, len(node.Childs["measValue"]))
-				aggregated := make(map[string]map[string]float64)
-
-				for _, mv := range node.Childs["measValue"] {
-					objLdn := mv.Attrs["measObjLdn"]
-					parts := strings.Split(objLdn, "/")
-					if len(parts) < 3 {
-						continue
-					}
-					prefixRu := strings.Join(parts[:3], "/")
-					resText := firstOrEmpty(mv.Childs["measResults"])
-					m := zipResults(typeText, resText)
-					if _, ok := aggregated[prefixRu]; !ok {
-						aggregated[prefixRu] = make(map[string]float64)
-					}
-					for k, v := range m {
-						aggregated[prefixRu][k] += parseFloat(v)
-					}
-				}
-				for prefix, vals := range aggregated {
-					m := map[string]string{"RU": prefix}
-					for k, v := range vals {
-						m[k] = floatToString(v)
-					}
-					res = append(res, m)
-				}
-				parsed = append(parsed, MeasInfo{MontypeName: "ENDC", Values: res})
-
-			case "RRU/Total PRB Usage":
-				typeText := firstOrEmpty(node.Childs["measTypes"])
-				res := make([]map[string]string, 0, len(node.Childs["measValue"]))
-				for _, mv := range node.Childs["measValue"] {
-					objLdn := mv.Attrs["measObjLdn"]
-					resText := firstOrEmpty(mv.Childs["measResults"])
-					m := zipResults(typeText, resText)
-					m["RU"] = objLdn
-					res = append(res, m)
-				}
-				parsed = append(parsed, MeasInfo{MontypeName: "PRB", Values: res})
-
-			case "RRC/RRC Connection Establishment":
-				typeText := firstOrEmpty(node.Childs["measTypes"])
-				for _, mv := range node.Childs["measValue"] {
-					objLdn := mv.Attrs["measObjLdn"]
-					parts := strings.Split(objLdn, "/")
-					if len(parts) < 3 {
-						continue
-					}
-					subRU := parts[1] + "/" + parts[2]
-					resText := firstOrEmpty(mv.Childs["measResults"])
-					m := zipResults(typeText, resText)
-					if _, ok := rrcMap[subRU]; !ok {
-						rrcMap[subRU] = make(map[string]float64)
-					}
-					rrcMap[subRU]["ConnEstabAtt"] += parseFloat(m["ConnEstabAtt"])
-					rrcMap[subRU]["ConnEstabSucc"] += parseFloat(m["ConnEstabSucc"])
-				}
+			suspects += acc.collect(rule, mi)
+		}
+	}
+	if suspects > 0 {
+		logger.Debugf("suspect measValue 제외: %d건 (%s)", suspects, name)
+	}
 
-			case "RRC/RRC Connection Re-establishment":
-				typeText := firstOrEmpty(node.Childs["measTypes"])
-				for _, mv := range node.Childs["measValue"] {
-					objLdn := mv.Attrs["measObjLdn"]
-					parts := strings.Split(objLdn, "/")
-					if len(parts) < 3 {
-						continue
-					}
-					subRU := parts[1] + "/" + parts[2]
-					resText := firstOrEmpty(mv.Childs["measResults"])
-					m := zipResults(typeText, resText)
-					if _, ok := rrcMap[subRU]; !ok {
-						rrcMap[subRU] = make(map[string]float64)
-					}
-					rrcMap[subRU]["ConnReEstabAtt"] += parseFloat(m["ConnReEstabAtt"])
-					rrcMap[subRU]["ConnReEstabSucc"] += parseFloat(m["ConnReEstabSucc"])
-				}
+	// 32.435 파일은 measCollec endTime 이 footer 에 있으나, 없으면 첫 granPeriod endTime 사용
+	if parsedResult.EndTime == "" {
+		for _, key := range accOrder {
+			if key.endTime != "" {
+				parsedResult.EndTime = key.endTime
+				break
 			}
 		}
 	}
 
-	// RRC 합산
-	rrcRes := make([]map[string]string, 0, len(rrcMap))
-	for key, v := range rrcMap {
-		rrcEntry := map[string]string{}
-		rrcEntry["RU"] = "/" + key
-		rrcAttempt := v["ConnEstabAtt"] + v["ConnReEstabAtt"]
-		rrcEntry["RRCATTEMPT"] = floatToString(rrcAttempt)
-		rate := 0.0
-		if rrcAttempt > 0 {
-			rate = (v["ConnEstabSucc"] + v["ConnReEstabSucc"]) / rrcAttempt * 100.0
+	// 규칙에 정의된 montype 순서대로 결과 정리
+	for _, mt := range rs.Montypes {
+		for _, key := range accOrder {
+			if key.montype != mt.Name {
+				continue
+			}
+			endTime := key.endTime
+			if endTime == "" {
+				endTime = parsedResult.EndTime
+			}
+			parsedResult.MeasResult = append(parsedResult.MeasResult, MeasResult{MontypeName: mt.Name, EndTime: endTime, Values: accs[key].values()})
 		}
-		rrcEntry["RRCSUCCRATE"] = floatToString(rate)
-		rrcRes = append(rrcRes, rrcEntry)
 	}
-	parsed = append(parsed, MeasInfo{MontypeName: "RRC", Values: rrcRes})
-
-	parsedResult.MeasResult = parsed
 
 	// 시간 파싱/가공
 	collectedDateTime := time.Now().Format("2006-01-02 15:04")
-	parsedEndTime, err := time.Parse("2006-01-02T15:04:05.000-07:00", parsedResult.EndTime)
-	if err != nil {
-		logger.Errorf("시간 파싱 오류: %v", err)
-		return
+	if _, err := parseEndTime(parsedResult.EndTime); err != nil {
+		if parseErr != nil {
+			return &ParseError{Kind: ErrXML, File: name, Err: parseErr}
+		}
+		return &ParseError{Kind: ErrTime, File: name, Err: err}
+	}
+	res.Members++
+	if res.EndTime == "" {
+		res.EndTime = parsedResult.EndTime
+		res.ManagedElement = parsedResult.ManagementElement
 	}
 	logger.Debugf("XML 처리 소요: %s", time.Since(start))
 
-	formattedEndTime := parsedEndTime.Format("2006-01-02 15:04")
-	measDate := parsedEndTime.Format("200601021504")
-	formattedTimeStamp := parsedEndTime.UTC().Format("2006-01-02T15:04:05.000Z")
-
 	// 메트릭 → ES 도큐먼트 전송
 	for _, measResult := range parsedResult.MeasResult {
 		mType := measResult.MontypeName
+		mt, _ := rs.Montype(mType)
 
-		switch {
-		case mType == "POWER":
-			for _, value := range measResult.Values {
-				ruParam := parsedResult.ManagementElement + value["RU"]
-				pm := roundToTwoDecimalPlaces(parseFloat(value["pmConsumedEnergy"]))
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "pmConsumedEnergy", pm, docChan)
+		parsedEndTime, err := parseEndTime(measResult.EndTime)
+		if err != nil {
+			logger.Errorf("시간 파싱 오류: %s %v", mType, err)
+			res.XMLErrors = append(res.XMLErrors, &ParseError{Kind: ErrTime, File: name, Err: err})
+			continue
+		}
+		formattedEndTime := parsedEndTime.Format("2006-01-02 15:04")
+		measDate := parsedEndTime.Format("200601021504")
+		formattedTimeStamp := parsedEndTime.UTC().Format("2006-01-02T15:04:05.000Z")
+
+		for _, value := range measResult.Values {
+			// 조회 키 후보 (montype 의 ru_param 규칙 순서), 매핑이 없으면 첫 번째 후보로 기록
+			keys := mt.Keys(parsedResult.ManagementElement, value["RU"], vp.RuParam)
+			ruParam := keys[0]
+			topo := mt.ParseTopology(value["RU"])
+			for i := range mt.Metrics {
+				metric := &mt.Metrics[i]
+				val, ok := metric.Evaluate(value)
+				if !ok {
+					continue
+				}
+				n, mapped := emitDocs(logger, store, keys, parsedEndTime, topo, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, metric.Field, val, res.File, docChan)
+				res.Docs[mType] += n
+				if !mapped {
+					res.unmapped[ruParam] = struct{}{}
+					res.Misses[Miss{RuParam: ruParam, ManagedElement: parsedResult.ManagementElement, Montype: mType}] += n
+				}
 			}
+		}
+	}
 
-		case mType == "MAXUE" && cfg.Logging.CollectionPeriod != 60:
-			for _, value := range measResult.Values {
-				ruParam := parsedResult.ManagementElement + value["RU"]
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "UEMax", int(parseFloat(value["UEMax"])), docChan)
-			}
+	if parseErr != nil {
+		return &ParseError{Kind: ErrXML, File: name, Err: parseErr}
+	}
+	return nil
+}
 
-		case mType == "MAC" && cfg.Logging.CollectionPeriod != 60:
-			for _, value := range measResult.Values {
-				ruParam := parsedResult.ManagementElement + value["RU"]
-				ul := roundToTwoDecimalPlaces(parseFloat(value["AirMacULKB"]) / 1024)
-				dl := roundToTwoDecimalPlaces(parseFloat(value["AirMacDLKB"]) / 1024)
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "MACUL", ul, docChan)
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "MACDL", dl, docChan)
-			}
+// parseEndTime: endTime 문자열 파싱. LSM(2006-01-02T15:04:05.000+09:00)과 32.435(밀리초 생략, Z 표기) 모두 허용.
+func parseEndTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
 
-		case mType == "ENDC" && cfg.Logging.CollectionPeriod != 60:
-			for _, value := range measResult.Values {
-				ruParam := parsedResult.ManagementElement + value["RU"]
-				att := parseFloat(value["EnDc_AddAtt"])
-				succ := parseFloat(value["EnDc_AddSucc"])
-				rate := 0.0
-				if att > 0 {
-					rate = (succ / att) * 100.0
-				}
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "ENDCATTEMPT", int(att), docChan)
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "ENDCSUCCRATE", roundToTwoDecimalPlaces(rate), docChan)
-			}
+// accKey: montype 누적 버퍼 키. 한 파일에 수집 주기가 다른 measInfo가 섞여 있으면 endTime 별로 나눔.
+type accKey struct {
+	montype string
+	endTime string
+}
 
-		case mType == "PRB" && cfg.Logging.CollectionPeriod != 60:
-			for _, value := range measResult.Values {
-				ruParam := parsedResult.ManagementElement + value["RU"]
-				dl := roundToTwoDecimalPlaces(parseFloat(value["PRBDownLinkAverage"]))
-				ul := roundToTwoDecimalPlaces(parseFloat(value["PRBUpLinkAverage"]))
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "PRBDL", dl, docChan)
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "PRBUL", ul, docChan)
-			}
+// montypeAcc: montype 하나로 모이는 measValue 행 버퍼. 같은 RU 키의 값은 measInfo 가 달라도 한 행으로 합침.
+// group_segments가 없는 measInfo는 measObjLdn 을 키로 값을 그대로, 있는 measInfo는 세그먼트 키별로 합산.
+type montypeAcc struct {
+	rows  map[string]map[string]string
+	sums  map[string]map[string]float64
+	order []string // RU 키 등장 순서
+}
 
-		case mType == "RRC" && cfg.Logging.CollectionPeriod != 60:
-			for _, value := range measResult.Values {
-				ruParam := parsedResult.ManagementElement + value["RU"]
-				attempt := int(parseFloat(value["RRCATTEMPT"]))
-				rate := roundToTwoDecimalPlaces(parseFloat(value["RRCSUCCRATE"]))
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "RRCATTEMPT", attempt, docChan)
-				emitDocs(logger, store, ruParam, &parsedResult, measDate, formattedEndTime, formattedTimeStamp, collectedDateTime, mType, "RRCSUCCRATE", rate, docChan)
+func newMontypeAcc() *montypeAcc {
+	return &montypeAcc{
+		rows: make(map[string]map[string]string),
+		sums: make(map[string]map[string]float64),
+	}
+}
+
+// collect: measInfo의 measValue들을 규칙에 따라 변환해 누적. suspect 로 표시된 값은 제외하고 그 건수를 반환.
+func (a *montypeAcc) collect(rule *rules.MeasInfoRule, mi *MeasInfo) int {
+	suspects := 0
+	for _, mv := range mi.Values {
+		if mv.Suspect {
+			suspects++
+			continue
+		}
+		m := renameResults(rule, mv.Results)
+		if len(m) == 0 {
+			continue // 이 montype 카운터가 없는 객체 (같은 measInfo를 여러 montype이 나눠 쓰는 경우)
+		}
+		if rule.GroupSegments == 0 {
+			row := a.row(mv.ObjLdn)
+			for k, v := range m {
+				row[k] = v
 			}
+			continue
+		}
+		key, ok := rule.GroupKey(mv.ObjLdn)
+		if !ok {
+			continue
+		}
+		a.row(key)
+		if _, ok := a.sums[key]; !ok {
+			a.sums[key] = make(map[string]float64)
+		}
+		for k, v := range m {
+			a.sums[key][k] += parseFloat(v)
+		}
+	}
+	return suspects
+}
+
+// row: RU 키에 해당하는 행 (없으면 생성)
+func (a *montypeAcc) row(key string) map[string]string {
+	row, ok := a.rows[key]
+	if !ok {
+		row = make(map[string]string)
+		a.rows[key] = row
+		a.order = append(a.order, key)
+	}
+	return row
+}
+
+// values: 누적된 행에 합산 결과를 합쳐 RU 키 등장 순서대로 반환
+func (a *montypeAcc) values() []map[string]string {
+	res := make([]map[string]string, 0, len(a.order))
+	for _, key := range a.order {
+		m := map[string]string{"RU": key}
+		for k, v := range a.rows[key] {
+			m[k] = v
 		}
+		for k, v := range a.sums[key] {
+			m[k] = floatToString(v)
+		}
+		res = append(res, m)
 	}
+	return res
 }
 
-// emitDocs: store에서 ruParam에 해당하는 매핑이 있으면 매핑별로 문서를 생성하여 전송,
-// 없으면 매핑 없이 기본 문서를 하나 생성해 전송
+// emitDocs: store에서 keys 를 순서대로 조회해 처음 찾은 매핑(at 시점에 유효했던 버전)이 있으면 매핑별로 문서를 생성하여 전송
+// (문서의 ru_param 은 찾은 키), 없으면 매핑 없이 첫 번째 키로 기본 문서를 하나 생성해 전송. 전송한 문서 수와 매핑 여부를 반환.
 func emitDocs(
 	logger *logrus.Logger,
 	store *store.Store,
-	ruParam string,
+	keys []string,
+	at time.Time,
+	topo *model.Topology,
 	parsedResult *MeasInfoData,
 	measDate, endTime, ts, collected, mType, field string,
 	val interface{},
+	sourceFile string,
 	docChan chan<- model.ElasticDocument,
-) {
-	if params, ok := store.Get(ruParam); ok {
+) (int, bool) {
+	for _, ruParam := range keys {
+		params, ok := store.GetAt(ruParam, at)
+		if !ok {
+			continue
+		}
 		for i := range params {
-			doc := buildDoc(&params[i], ruParam, parsedResult.ManagementElement, measDate, endTime, ts, collected, mType, field, val)
+			doc := buildDoc(&params[i], topo, ruParam, parsedResult.ManagementElement, parsedResult.Generation, measDate, endTime, ts, collected, mType, field, val)
+			doc.SourceFile = &sourceFile
 			docChan <- doc
 		}
-	} else {
-		logger.Debugf("ru_param not found: %s", ruParam)
-		doc := buildDoc(nil, ruParam, parsedResult.ManagementElement, measDate, endTime, ts, collected, mType, field, val)
-		docChan <- doc
+		return len(params), true
 	}
+	logger.Debugf("ru_param not found: %s", strings.Join(keys, ", "))
+	doc := buildDoc(nil, topo, keys[0], parsedResult.ManagementElement, parsedResult.Generation, measDate, endTime, ts, collected, mType, field, val)
+	doc.SourceFile = &sourceFile
+	docChan <- doc
+	return 1, false
 }
 
-// buildDoc: RuMapping (있다면) 정보를 사용해 model.ElasticDocument 최종 도큐먼트 완성
+// buildDoc: RuMapping (있다면) 정보와 measObjLdn 의 RU 위치(topo)를 사용해 model.ElasticDocument 최종 도큐먼트 완성
 func buildDoc(
 	m *model.RuMapping,
-	ruParam, equipID, measDate, endTime, ts, collected, mType, field string,
+	topo *model.Topology,
+	ruParam, equipID, generation, measDate, endTime, ts, collected, mType, field string,
 	val interface{},
 ) model.ElasticDocument {
 	rp := ruParam
@@ -307,6 +326,7 @@ func buildDoc(
 	tsp := ts
 	eq := equipID
 	cd := collected
+	gen := generation
 	unknown := "UNKNOWN"
 
 	var emsID, duID, cellID, cellNum, ruName *string
@@ -340,6 +360,12 @@ func buildDoc(
 		emsID, duID, cellID, cellNum, ruName = &unknown, &unknown, &unknown, &unknown, &unknown
 	}
 
+	var attrs map[string]string
+	var location *model.GeoPoint
+	if m != nil {
+		attrs, location = m.Attrs, m.Location
+	}
+
 	return model.ElasticDocument{
 		EmsID:       emsID,
 		DuId:        duID,
@@ -354,53 +380,19 @@ func buildDoc(
 		Timestamp:   &tsp,
 		EquipID:     &eq,
 		CollectDate: &cd,
+		Generation:  &gen,
+		Attrs:       attrs,
+		Location:    location,
+		Topology:    topo,
 	}
 }
 
-func firstOrEmpty(nodes []xmlparser.XMLElement) string {
-	if len(nodes) == 0 {
-		return ""
-	}
-	return nodes[0].InnerText
-}
-
-// zipResults: measTypes와 measResults 문자열을 짝지어 매핑
-func zipResults(typesStr, resultsStr string) map[string]string {
-	types := strings.Fields(typesStr)
-	values := strings.Fields(resultsStr)
-
-	m := make(map[string]string, len(types))
-	for i, t := range types {
-		var val string
-		if i < len(values) {
-			val = values[i]
-		}
-
-		switch t {
-		case "RuPowerAvg(W)":
-			m["pmConsumedEnergy"] = val
-		case "ConnNoMax(count)":
-			m["UEMax"] = val
-		case "AirMacULByte(Kbytes)":
-			m["AirMacULKB"] = val
-		case "AirMacDLByte(Kbytes)":
-			m["AirMacDLKB"] = val
-		case "EnDc_AddAtt(count)":
-			m["EnDc_AddAtt"] = val
-		case "EnDc_AddSucc(count)":
-			m["EnDc_AddSucc"] = val
-		case "TotPrbDLAvg(%)":
-			m["PRBDownLinkAverage"] = val
-		case "TotPrbULAvg(%)":
-			m["PRBUpLinkAverage"] = val
-		case "ConnEstabAtt(count)":
-			m["ConnEstabAtt"] = val
-		case "ConnEstabSucc(count)":
-			m["ConnEstabSucc"] = val
-		case "ConnReEstabAtt(count)":
-			m["ConnReEstabAtt"] = val
-		case "ConnReEstabSucc(count)":
-			m["ConnReEstabSucc"] = val
+// renameResults: measType → 결과 값을 규칙의 필드명으로 변환 (규칙에 없는 measType은 버림)
+func renameResults(rule *rules.MeasInfoRule, results map[string]string) map[string]string {
+	m := make(map[string]string, len(results))
+	for t, val := range results {
+		if field, ok := rule.Rename(t); ok {
+			m[field] = val
 		}
 	}
 	return m
@@ -421,9 +413,3 @@ func parseFloat(value string) float64 {
 func floatToString(value float64) string {
 	return strconv.FormatFloat(value, 'f', 2, 64)
 }
-
-// 소수점 둘째자리까지 반올림
-func roundToTwoDecimalPlaces(value float64) float64 {
-	const factor = 100.0
-	return float64(int64(value*factor+0.5)) / factor
-}
@@ -0,0 +1,221 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"same-parser/internal/config"
+	"same-parser/internal/model"
+	"same-parser/internal/rules"
+	"same-parser/internal/store"
+	"testing"
+)
+
+// TestProcessXMLSamsungLTE: 내장 Samsung LTE 규칙의 결과가 기존 하드코딩 집계와 같은지 확인.
+// - ENDC/RRC 는 /UMP00/cNumX 단위(group_segments: 3)로 합산, RRC 는 수립+재수립 합계로 계산
+// - 시도 0 인 성공률은 0 (기존 동작)
+// - PRB/MAXUE/POWER 는 measObjLdn 그대로, 소수 둘째 자리 반올림
+func TestProcessXMLSamsungLTE(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg := &config.Config{}
+	cfg.Logging.CollectionPeriod = 15
+
+	rs, err := rules.Load("", "SAMSUNG", "LTE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp, err := Lookup("SAMSUNG", "LTE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docChan := make(chan model.ElasticDocument, 100)
+	res, err := ProcessXML(logger, cfg, rs, vp, store.NewStore(), "testdata/samsung_lte.xml", docChan)
+	close(docChan)
+	if err != nil {
+		t.Fatalf("ProcessXML: %v", err)
+	}
+
+	got := make(map[string]interface{})
+	for doc := range docChan {
+		key := *doc.MontypeName + " " + *doc.RuParam + " " + doc.Data.Field
+		if _, dup := got[key]; dup {
+			t.Errorf("duplicate document %s", key)
+		}
+		got[key] = doc.Data.Result
+		if *doc.MeasDate != "202405011015" || *doc.EquipID != "DU001" {
+			t.Errorf("%s: measdate=%s equip_id=%s", key, *doc.MeasDate, *doc.EquipID)
+		}
+	}
+
+	want := map[string]interface{}{
+		"POWER DU001/UMP00/BID1/RuPort0/Cascade0 pmConsumedEnergy": 123.46,
+		"MAXUE DU001/UMP00/cNum1 UEMax":                            17,
+		"ENDC DU001/UMP00/cNum1 ENDCATTEMPT":                       20,
+		"ENDC DU001/UMP00/cNum1 ENDCSUCCRATE":                      85.0,
+		"ENDC DU001/UMP00/cNum2 ENDCATTEMPT":                       0,
+		"ENDC DU001/UMP00/cNum2 ENDCSUCCRATE":                      0.0,
+		"PRB DU001/UMP00/BID1/RuPort0/Cascade0 PRBDL":              45.68,
+		"PRB DU001/UMP00/BID1/RuPort0/Cascade0 PRBUL":              12.3,
+		"RRC DU001/UMP00/cNum1 RRCATTEMPT":                         160,
+		"RRC DU001/UMP00/cNum1 RRCSUCCRATE":                        91.25,
+	}
+	for k, w := range want {
+		g, ok := got[k]
+		if !ok {
+			t.Errorf("missing document %s", k)
+			continue
+		}
+		if g != w {
+			t.Errorf("%s = %v (%T), want %v (%T)", k, g, g, w, w)
+		}
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			t.Errorf("unexpected document %s = %v", k, got[k])
+		}
+	}
+
+	if res.TotalDocs() != len(want) {
+		t.Errorf("TotalDocs = %d, want %d", res.TotalDocs(), len(want))
+	}
+	if res.EndTime != "2024-05-01T10:15:00.000+09:00" || res.ManagedElement != "DU001" || res.Members != 1 {
+		t.Errorf("result = endTime %q me %q members %d", res.EndTime, res.ManagedElement, res.Members)
+	}
+}
+
+// TestProcessXMLErrorKinds: 압축 손상·미지원 구조는 재시도하지 않고, 파일 열기 실패만 재시도
+func TestProcessXMLErrorKinds(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg := &config.Config{}
+	cfg.Logging.CollectionPeriod = 15
+	rs, err := rules.Load("", "SAMSUNG", "LTE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp, err := Lookup("SAMSUNG", "LTE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml, err := os.ReadFile("testdata/samsung_lte.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gz := func(b []byte) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(b)
+		zw.Close()
+		return buf.Bytes()
+	}
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, _ := zw.Create("A.xml")
+	w.Write(xml)
+	zw.Close()
+	full := gz(xml)
+
+	dir := t.TempDir()
+	cases := []struct {
+		name      string
+		content   []byte // nil 이면 파일을 만들지 않음
+		kind      ErrorKind
+		retryable bool
+	}{
+		{"ok.xml.gz", full, 0, false},
+		{"truncated.xml.gz", full[:len(full)/2], ErrCorrupt, false},
+		{"header.xml.gz", full[:4], ErrCorrupt, false},
+		{"nested.zip.gz", gz(zipped.Bytes()), ErrUnsupported, false},
+		{"broken.zip", zipped.Bytes()[:zipped.Len()-10], ErrCorrupt, false},
+		{"missing.xml", nil, ErrOpen, true},
+	}
+	for _, tc := range cases {
+		p := filepath.Join(dir, tc.name)
+		if tc.content != nil {
+			if err := os.WriteFile(p, tc.content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		docChan := make(chan model.ElasticDocument, 100)
+		_, err := ProcessXML(logger, cfg, rs, vp, store.NewStore(), p, docChan)
+		if tc.kind == 0 {
+			if err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+			}
+			continue
+		}
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Kind != tc.kind {
+			t.Errorf("%s: err = %v, want kind %s", tc.name, err, tc.kind)
+			continue
+		}
+		if IsRetryable(err) != tc.retryable {
+			t.Errorf("%s: IsRetryable = %t, want %t", tc.name, IsRetryable(err), tc.retryable)
+		}
+	}
+}
+
+// processFixture: 벤더/세대 기본 규칙으로 fixture 를 처리해 "montype ru_param field" → 결과 값과 문서 목록 반환
+func processFixture(t *testing.T, vendor, generation, path string, period int) (map[string]interface{}, []model.ElasticDocument, *ParseResult) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg := &config.Config{}
+	cfg.Logging.CollectionPeriod = period
+	rs, err := rules.Load("", vendor, generation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp, err := Lookup(vendor, generation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docChan := make(chan model.ElasticDocument, 100)
+	res, err := ProcessXML(logger, cfg, rs, vp, store.NewStore(), path, docChan)
+	close(docChan)
+	if err != nil {
+		t.Fatalf("ProcessXML: %v", err)
+	}
+	got := make(map[string]interface{})
+	var docs []model.ElasticDocument
+	for doc := range docChan {
+		key := *doc.MontypeName + " " + *doc.RuParam + " " + doc.Data.Field
+		if _, dup := got[key]; dup {
+			t.Errorf("duplicate document %s", key)
+		}
+		got[key] = doc.Data.Result
+		docs = append(docs, doc)
+	}
+	return got, docs, res
+}
+
+// checkDocs: got 이 want 와 정확히 같은지 (값과 타입 모두) 확인
+func checkDocs(t *testing.T, got, want map[string]interface{}) {
+	t.Helper()
+	for k, w := range want {
+		g, ok := got[k]
+		if !ok {
+			t.Errorf("missing document %s", k)
+			continue
+		}
+		if g != w {
+			t.Errorf("%s = %v (%T), want %v (%T)", k, g, g, w, w)
+		}
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			t.Errorf("unexpected document %s = %v", k, got[k])
+		}
+	}
+}
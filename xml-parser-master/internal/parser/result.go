@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ParseResult: ProcessXML 처리 결과 (파일 하나, 묶음이면 멤버 전체 합계)
+type ParseResult struct {
+	File             string         `json:"file"`
+	EndTime          string         `json:"endTime"`        // 첫 번째로 성공한 멤버의 endTime
+	ManagedElement   string         `json:"managedElement"` // 첫 번째로 성공한 멤버의 managedElement
+	Members          int            `json:"members"`        // 처리한 XML 수
+	Docs             map[string]int `json:"docs"`           // montype → 전송 문서 수
+	UnmappedRuParams []string       `json:"unmappedRuParams"`
+	Misses           map[Miss]int   `json:"-"` // 매핑 누락 위치 → 매핑 없이 전송한 문서 수
+	XMLErrors        []error        `json:"-"` // 멤버/measInfo 단위 오류
+	Duration         time.Duration  `json:"duration"`
+
+	unmapped map[string]struct{}
+}
+
+// Miss: ru_mapping 에 없는 ru_param 이 나온 위치
+type Miss struct {
+	RuParam        string
+	ManagedElement string // DU
+	Montype        string
+}
+
+func newParseResult(file string) *ParseResult {
+	return &ParseResult{
+		File:     file,
+		Docs:     make(map[string]int),
+		Misses:   make(map[Miss]int),
+		unmapped: make(map[string]struct{}),
+	}
+}
+
+// TotalDocs: 전송한 문서 수 합계
+func (r *ParseResult) TotalDocs() int {
+	total := 0
+	for _, n := range r.Docs {
+		total += n
+	}
+	return total
+}
+
+// finish: 정렬된 미매핑 목록 정리 및 소요 시간 기록
+func (r *ParseResult) finish(start time.Time) {
+	r.UnmappedRuParams = make([]string, 0, len(r.unmapped))
+	for k := range r.unmapped {
+		r.UnmappedRuParams = append(r.UnmappedRuParams, k)
+	}
+	sort.Strings(r.UnmappedRuParams)
+	r.Duration = time.Since(start)
+}
+
+// ErrorKind: ProcessXML 실패 유형
+type ErrorKind int
+
+const (
+	ErrOpen        ErrorKind = iota + 1 // 파일 열기/읽기 실패 (일시적 입출력 오류)
+	ErrXML                              // XML 구조 오류
+	ErrTime                             // endTime 누락/형식 오류
+	ErrUnsupported                      // 지원하지 않는 묶음 구조 (스트림 안의 zip 등)
+	ErrCorrupt                          // 압축/묶음 손상 (잘린 gzip, 깨진 tar/zip 등)
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrOpen:
+		return "open"
+	case ErrXML:
+		return "xml"
+	case ErrTime:
+		return "time"
+	case ErrUnsupported:
+		return "unsupported"
+	case ErrCorrupt:
+		return "corrupt"
+	}
+	return "unknown"
+}
+
+// ParseError: ProcessXML 이 반환하는 오류. Kind 로 재시도/격리 여부를 판단.
+type ParseError struct {
+	Kind ErrorKind
+	File string // 파일 또는 "파일!멤버"
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s error: %s: %v", e.Kind, e.File, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable: 재시도로 해결될 수 있는 오류인지 (파일 열기/읽기 실패만 해당, 손상·미지원 구조는 제외)
+func (e *ParseError) Retryable() bool {
+	return e.Kind == ErrOpen
+}
+
+// IsRetryable: err 가 재시도 가능한 ParseError 인지
+func IsRetryable(err error) bool {
+	var pe *ParseError
+	return errors.As(err, &pe) && pe.Retryable()
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+
+	xmlparser "github.com/tamerh/xml-stream-parser"
+)
+
+func init() {
+	Register("SAMSUNG", "LTE", samsungParser{})
+	Register("SAMSUNG", "NR", samsungParser{})
+}
+
+// samsungParser: Samsung LSM measCollecFile (LSM 문자열 형식과 32.435 요소 형식 모두 처리).
+// LTE/NR 은 파일 형식이 같고 measInfoId 만 다르므로 세대별 규칙으로 구분.
+type samsungParser struct{}
+
+func (samsungParser) Parse(r io.Reader, want func(string) bool) (*MeasCollec, error) {
+	return parseMeasCollecFile(r, want, func(attrs map[string]string) string {
+		return attrs["localDn"] // DU
+	})
+}
+
+// RuParam: DU + measObjLdn (예: DU123 + /UMP00/BID1/RuPort2/Cascade0)
+func (samsungParser) RuParam(managedElement, objLdn string) string {
+	return managedElement + objLdn
+}
+
+// parseMeasCollecFile: 3GPP TS 32.435 measCollecFile 공통 스트리밍 파서.
+// meName 으로 managedElement 속성에서 equip_id 로 쓸 값을 고름.
+// XML 오류가 나도 스트림을 끝까지 읽고(스트림 파서는 오류 뒤 종료), 첫 번째 오류를 반환.
+func parseMeasCollecFile(r io.Reader, want func(string) bool, meName func(attrs map[string]string) string) (*MeasCollec, error) {
+	parser := xmlparser.NewXMLParser(bufio.NewReader(r), "measInfo", "measCollec", "managedElement")
+
+	mc := &MeasCollec{}
+	var xmlErr error
+	for node := range parser.Stream() {
+		if node.Err != nil {
+			if xmlErr == nil {
+				xmlErr = node.Err
+			}
+			continue
+		}
+
+		switch node.Name {
+		case "measCollec":
+			if t, ok := node.Attrs["endTime"]; ok {
+				mc.EndTime = t
+			}
+		case "managedElement":
+			mc.ManagedElement = meName(node.Attrs)
+		case "measInfo":
+			if !want(node.Attrs["measInfoId"]) {
+				continue
+			}
+			mi, err := decodeMeasInfo(node)
+			if err != nil {
+				mc.Errors = append(mc.Errors, err)
+				continue
+			}
+			mc.MeasInfos = append(mc.MeasInfos, mi)
+		}
+	}
+	return mc, xmlErr
+}
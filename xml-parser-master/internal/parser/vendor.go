@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MeasCollec: 벤더 파서가 PM 파일 하나를 읽어 만든 정규화된 측정 결과
+type MeasCollec struct {
+	EndTime        string     `json:"endTime"`        // 파일 수집 종료 시각 (RFC3339)
+	ManagedElement string     `json:"managedElement"` // DU/eNB 식별자 (equip_id)
+	MeasInfos      []MeasInfo `json:"measInfos"`
+	Errors         []error    `json:"-"` // 건너뛴 measInfo 디코딩 오류
+}
+
+// Parser: 벤더별 PM 파일 파서 (입력 스트림 → 정규화된 측정 결과)
+type Parser interface {
+	// Parse: r 을 끝까지 읽어 want(measInfoId) 가 true 인 measInfo 만 디코딩.
+	// XML 오류로 중단되어도 그때까지 읽은 결과를 함께 반환.
+	Parse(r io.Reader, want func(measInfoID string) bool) (*MeasCollec, error)
+	// RuParam: managedElement 와 measObjLdn(또는 합산 키)으로 ru_mapping 조회 키 생성
+	RuParam(managedElement, objLdn string) string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Parser)
+)
+
+func registryKey(vendor, generation string) string {
+	return strings.ToUpper(vendor) + "/" + strings.ToUpper(generation)
+}
+
+// Register: 벤더+세대(LTE, NR)에 파서 등록. 각 구현 파일의 init 에서 호출.
+func Register(vendor, generation string, p Parser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[registryKey(vendor, generation)] = p
+}
+
+// Lookup: 벤더+세대에 해당하는 파서 조회
+func Lookup(vendor, generation string) (Parser, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if p, ok := registry[registryKey(vendor, generation)]; ok {
+		return p, nil
+	}
+	keys := make([]string, 0, len(registry))
+	for k := range registry {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return nil, fmt.Errorf("no parser for %s (available: %s)", registryKey(vendor, generation), strings.Join(keys, ", "))
+}
@@ -0,0 +1,25 @@
+package parser
+
+import (
+	"same-parser/internal/rules"
+	"testing"
+)
+
+// TestLookupHasDefaultRules: 등록된 벤더/세대는 모두 내장 기본 규칙이 있어야 함 (없으면 시작 시 rules.Default 실패)
+func TestLookupHasDefaultRules(t *testing.T) {
+	for _, vendor := range []string{"SAMSUNG", "ERICSSON", "NOKIA"} {
+		for _, gen := range []string{"LTE", "NR"} {
+			_, lookupErr := Lookup(vendor, gen)
+			_, rulesErr := rules.Default(vendor, gen)
+			if (lookupErr == nil) != (rulesErr == nil) {
+				t.Errorf("%s/%s: parser err = %v, default rules err = %v", vendor, gen, lookupErr, rulesErr)
+			}
+		}
+	}
+	if _, err := Lookup("ericsson", "nr"); err == nil {
+		t.Error("ERICSSON/NR registered without default rules")
+	}
+	if _, err := Lookup("samsung", "lte"); err != nil {
+		t.Error(err)
+	}
+}
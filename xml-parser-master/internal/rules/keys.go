@@ -0,0 +1,178 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// placeholder: 키 템플릿 변수 ({name})
+var placeholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// RuParamRule: ru_mapping 조회 키(ru_param) 생성 규칙.
+// Keys 를 순서대로 만들어 ru_mapping 에서 처음 찾은 키를 사용 (예: 그대로 → UMP 세그먼트 보정 → DU + cNum 셀 단위 키).
+type RuParamRule struct {
+	Keys []KeyRule `yaml:"keys"`
+}
+
+// KeyRule: 조회 키 후보 하나. 적용 순서: segments → match → template → rewrite → case.
+type KeyRule struct {
+	Segments []int     `yaml:"segments"` // measObjLdn 을 "/" 로 나눈 [시작, 끝) 세그먼트만 사용 (맨 앞 빈 세그먼트가 0번, 음수는 뒤에서부터, 끝 생략 시 마지막까지)
+	Match    string    `yaml:"match"`    // measObjLdn 정규식, 맞지 않으면 이 후보는 건너뜀. 이름 있는 그룹((?P<name>...))은 템플릿 변수로 사용
+	Template string    `yaml:"template"` // {ru_param}(벤더 기본 키, 기본값) {me}(managedElement) {ldn}(measObjLdn) 및 match 그룹
+	Rewrite  []Rewrite `yaml:"rewrite"`  // 만든 키에 차례로 적용할 정규식 치환
+	Case     string    `yaml:"case"`     // upper | lower, 비우면 그대로
+
+	match *regexp.Regexp
+}
+
+// Rewrite: 정규식 치환 (replace 에 $1, ${name} 사용 가능)
+type Rewrite struct {
+	Pattern string `yaml:"pattern"`
+	Replace string `yaml:"replace"`
+
+	re *regexp.Regexp
+}
+
+// compile: 키 규칙 검증 및 정규식 컴파일
+func (r *RuParamRule) compile() error {
+	for i := range r.Keys {
+		if err := r.Keys[i].compile(); err != nil {
+			return fmt.Errorf("ru_param keys[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (k *KeyRule) compile() error {
+	if len(k.Segments) > 2 {
+		return fmt.Errorf("segments: expected [start] or [start, end]")
+	}
+	if k.Template == "" {
+		k.Template = "{ru_param}"
+	}
+	vars := map[string]bool{"ru_param": true, "me": true, "ldn": true}
+	if k.Match != "" {
+		re, err := regexp.Compile(k.Match)
+		if err != nil {
+			return fmt.Errorf("match: %w", err)
+		}
+		k.match = re
+		for _, name := range re.SubexpNames() {
+			if name != "" {
+				vars[name] = true
+			}
+		}
+	}
+	for _, m := range placeholder.FindAllStringSubmatch(k.Template, -1) {
+		if !vars[m[1]] {
+			return fmt.Errorf("template %q: unknown variable {%s}", k.Template, m[1])
+		}
+	}
+	for i := range k.Rewrite {
+		rw := &k.Rewrite[i]
+		if rw.Pattern == "" {
+			return fmt.Errorf("rewrite[%d]: pattern is required", i)
+		}
+		re, err := regexp.Compile(rw.Pattern)
+		if err != nil {
+			return fmt.Errorf("rewrite[%d]: %w", i, err)
+		}
+		rw.re = re
+	}
+	switch k.Case {
+	case "", "upper", "lower":
+	default:
+		return fmt.Errorf("unknown case %q", k.Case)
+	}
+	return nil
+}
+
+// Keys: managedElement 와 measObjLdn(또는 합산 키)으로 조회 키 후보를 순서대로 만듦 (빈 키, 중복 제외).
+// 규칙이 없거나 맞는 후보가 없으면 벤더 기본 키(vendorKey) 하나.
+func (mt *MontypeRule) Keys(managedElement, objLdn string, vendorKey func(managedElement, objLdn string) string) []string {
+	if mt.RuParam == nil || len(mt.RuParam.Keys) == 0 {
+		return []string{vendorKey(managedElement, objLdn)}
+	}
+	keys := make([]string, 0, len(mt.RuParam.Keys))
+	for i := range mt.RuParam.Keys {
+		key, ok := mt.RuParam.Keys[i].build(managedElement, objLdn, vendorKey)
+		if !ok || key == "" || contains(keys, key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return []string{vendorKey(managedElement, objLdn)}
+	}
+	return keys
+}
+
+// build: 후보 키 하나 생성, segments 가 부족하거나 match 가 맞지 않으면 false
+func (k *KeyRule) build(managedElement, objLdn string, vendorKey func(managedElement, objLdn string) string) (string, bool) {
+	ldn := objLdn
+	if len(k.Segments) > 0 {
+		var ok bool
+		if ldn, ok = selectSegments(objLdn, k.Segments); !ok {
+			return "", false
+		}
+	}
+	vars := map[string]string{"me": managedElement, "ldn": ldn}
+	if k.match != nil {
+		m := k.match.FindStringSubmatch(ldn)
+		if m == nil {
+			return "", false
+		}
+		for i, name := range k.match.SubexpNames() {
+			if name != "" {
+				vars[name] = m[i]
+			}
+		}
+	}
+	key := placeholder.ReplaceAllStringFunc(k.Template, func(p string) string {
+		name := p[1 : len(p)-1]
+		if name == "ru_param" {
+			return vendorKey(managedElement, ldn)
+		}
+		return vars[name]
+	})
+	for _, rw := range k.Rewrite {
+		key = rw.re.ReplaceAllString(key, rw.Replace)
+	}
+	switch k.Case {
+	case "upper":
+		key = strings.ToUpper(key)
+	case "lower":
+		key = strings.ToLower(key)
+	}
+	return key, true
+}
+
+// selectSegments: "/" 로 나눈 세그먼트 중 [start, end) 범위 (GroupKey 와 같이 맨 앞 빈 세그먼트도 0번으로 셈)
+func selectSegments(objLdn string, seg []int) (string, bool) {
+	parts := strings.Split(objLdn, "/")
+	n := len(parts)
+	start, end := seg[0], n
+	if len(seg) == 2 {
+		end = seg[1]
+	}
+	if start < 0 {
+		start += n
+	}
+	if end < 0 {
+		end += n
+	}
+	if start < 0 || end > n || start >= end {
+		return "", false
+	}
+	return strings.Join(parts[start:end], "/"), true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// samsungKey: parser.samsungParser.RuParam 과 같은 벤더 기본 키 (DU + measObjLdn)
+func samsungKey(me, ldn string) string {
+	return me + ldn
+}
+
+func mustParse(t *testing.T, y string) *Ruleset {
+	t.Helper()
+	rs, err := parse([]byte(y))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+// keysRuleset: ru_param 규칙만 바꿔 끼울 수 있는 최소 규칙
+func keysRuleset(t *testing.T, ruParam string) *MontypeRule {
+	t.Helper()
+	rs := mustParse(t, `
+meas_infos:
+  - meas_info_id: A
+    montype: M
+    counters: {a: a}
+montypes:
+  - name: M
+    metrics:
+      - field: a
+        expr: a
+`+ruParam)
+	mt, _ := rs.Montype("M")
+	return mt
+}
+
+// TestSamsungLTEDefaultKeys: 기본 Samsung LTE 규칙이 LSM measObjLdn 마다 조회하는 키와 순서.
+// 첫 번째 키는 기존과 같은 DU + measObjLdn 이므로 매핑이 있으면 결과가 같고,
+// 없을 때만 BID/RuPort/Cascade 표기 보정 키, 그다음 셀(cNum) 단위 키로 조회함 (찾으면 문서 ru_param 도 그 키).
+func TestSamsungLTEDefaultKeys(t *testing.T) {
+	rs, err := Default("SAMSUNG", "LTE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		ldn  string
+		want []string
+	}{
+		// 보정 키가 원래 키와 같으면 중복 제외
+		{"/UMP00/BID1/RuPort0/Cascade0", []string{"DU001/UMP00/BID1/RuPort0/Cascade0"}},
+		{"/ump01/bid1/ruport0/cascade0", []string{"DU001/ump01/bid1/ruport0/cascade0", "DU001/UMP00/BID1/RuPort0/Cascade0"}},
+		{"BID1/RuPort0/Cascade0", []string{"DU001BID1/RuPort0/Cascade0", "DU001/UMP00/BID1/RuPort0/Cascade0"}},
+		// 셀 하위 객체는 셀 단위 키로 대체 조회
+		{"/UMP00/cNum1/Carrier0", []string{"DU001/UMP00/cNum1/Carrier0", "DU001/UMP00/cNum1"}},
+		{"/UMP00/cNum1/Plmn0", []string{"DU001/UMP00/cNum1/Plmn0", "DU001/UMP00/cNum1"}},
+		{"/UMP00/cNum1", []string{"DU001/UMP00/cNum1"}},
+		// RU 경로 뒤에 셀이 붙으면 RU 보정($ 고정)은 맞지 않고 셀 키만
+		{"/UMP00/BID1/RuPort0/Cascade0/cNum3", []string{"DU001/UMP00/BID1/RuPort0/Cascade0/cNum3", "DU001/UMP00/cNum3"}},
+		{"/UMP00/Other0", []string{"DU001/UMP00/Other0"}},
+	}
+	for _, mt := range rs.Montypes {
+		for _, tc := range cases {
+			if got := mt.Keys("DU001", tc.ldn, samsungKey); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%s Keys(%q) = %q, want %q", mt.Name, tc.ldn, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cases := []struct {
+		name    string
+		ruParam string
+		me, ldn string
+		want    []string
+	}{
+		{
+			name: "no rule uses vendor key",
+			me:   "DU1", ldn: "/A/B",
+			want: []string{"DU1/A/B"},
+		},
+		{
+			name: "template variables",
+			ruParam: `
+ru_param:
+  keys:
+    - template: "{me}|{ldn}|{ru_param}"`,
+			me: "DU1", ldn: "/A/B",
+			want: []string{"DU1|/A/B|DU1/A/B"},
+		},
+		{
+			name: "segments narrow ldn and ru_param",
+			ruParam: `
+ru_param:
+  keys:
+    - segments: [0, 2]
+    - segments: [-2]
+      template: "{me}:{ldn}"
+    - segments: [1, -1]
+      template: "{ldn}"`,
+			me: "DU1", ldn: "/A/B/C",
+			want: []string{"DU1/A", "DU1:B/C", "A/B"},
+		},
+		{
+			name: "match groups, rewrite and case",
+			ruParam: `
+ru_param:
+  keys:
+    - match: 'cell(?P<n>\d+)'
+      template: "{me}-c{n}"
+      case: upper
+    - template: "{ldn}"
+      rewrite:
+        - pattern: '^/'
+          replace: ''
+        - pattern: '(\w)/(\w)'
+          replace: '${1}_${2}'
+      case: lower`,
+			me: "du1", ldn: "/X/cell07",
+			want: []string{"DU1-C07", "x_cell07"},
+		},
+		{
+			name: "unmatched and out of range candidates skipped, duplicates and empty keys dropped",
+			ruParam: `
+ru_param:
+  keys:
+    - match: 'nomatch'
+    - segments: [5]
+    - template: "{ldn}"
+      rewrite:
+        - pattern: '.*'
+          replace: ''
+    - template: "{me}{ldn}"
+    - template: "{ru_param}"`,
+			me: "DU1", ldn: "/A",
+			want: []string{"DU1/A"},
+		},
+		{
+			name: "no candidate matches falls back to vendor key",
+			ruParam: `
+ru_param:
+  keys:
+    - match: 'nomatch'
+      template: "x"`,
+			me: "DU1", ldn: "/A",
+			want: []string{"DU1/A"},
+		},
+	}
+	for _, tc := range cases {
+		mt := keysRuleset(t, tc.ruParam)
+		if got := mt.Keys(tc.me, tc.ldn, samsungKey); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Keys = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestKeysMontypeOverride: montype 에 ru_param 규칙이 있으면 최상위 규칙 대신 사용
+func TestKeysMontypeOverride(t *testing.T) {
+	rs := mustParse(t, `
+meas_infos:
+  - {meas_info_id: A, montype: M, counters: {a: a}}
+  - {meas_info_id: B, montype: N, counters: {a: a}}
+montypes:
+  - name: M
+    metrics: [{field: a, expr: a}]
+  - name: N
+    ru_param:
+      keys: [{template: "N:{ldn}"}]
+    metrics: [{field: a, expr: a}]
+ru_param:
+  keys: [{template: "TOP:{ldn}"}]
+`)
+	m, _ := rs.Montype("M")
+	n, _ := rs.Montype("N")
+	if got := m.Keys("DU1", "/A", samsungKey); !reflect.DeepEqual(got, []string{"TOP:/A"}) {
+		t.Errorf("M keys = %q", got)
+	}
+	if got := n.Keys("DU1", "/A", samsungKey); !reflect.DeepEqual(got, []string{"N:/A"}) {
+		t.Errorf("N keys = %q", got)
+	}
+}
+
+func TestSelectSegments(t *testing.T) {
+	cases := []struct {
+		ldn  string
+		seg  []int
+		want string
+		ok   bool
+	}{
+		{"/UMP00/cNum1/Plmn0", []int{0, 3}, "/UMP00/cNum1", true},
+		{"/UMP00/cNum1/Plmn0", []int{1}, "UMP00/cNum1/Plmn0", true},
+		{"/UMP00/cNum1/Plmn0", []int{-2}, "cNum1/Plmn0", true},
+		{"/UMP00/cNum1/Plmn0", []int{1, -1}, "UMP00/cNum1", true},
+		{"/UMP00/cNum1/Plmn0", []int{-4, 2}, "/UMP00", true},
+		{"/UMP00/cNum1/Plmn0", []int{0, 5}, "", false},
+		{"/UMP00/cNum1/Plmn0", []int{-5}, "", false},
+		{"/UMP00/cNum1/Plmn0", []int{2, 2}, "", false},
+		{"/UMP00/cNum1/Plmn0", []int{4}, "", false},
+	}
+	for _, tc := range cases {
+		got, ok := selectSegments(tc.ldn, tc.seg)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("selectSegments(%q, %v) = %q, %t; want %q, %t", tc.ldn, tc.seg, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestKeyRuleCompileErrors(t *testing.T) {
+	cases := []struct {
+		rule string
+		want string
+	}{
+		{`{segments: [0, 1, 2]}`, "segments"},
+		{`{match: '('}`, "match"},
+		{`{template: "{nope}"}`, "unknown variable {nope}"},
+		{`{match: '(?P<n>\d)', template: "{m}"}`, "unknown variable {m}"},
+		{`{rewrite: [{replace: x}]}`, "pattern is required"},
+		{`{rewrite: [{pattern: '['}]}`, "rewrite[0]"},
+		{`{case: title}`, "unknown case"},
+	}
+	for _, tc := range cases {
+		_, err := parse([]byte(`
+meas_infos: [{meas_info_id: A, montype: M, counters: {a: a}}]
+montypes: [{name: M, metrics: [{field: a, expr: a}]}]
+ru_param:
+  keys: [` + tc.rule + `]`))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.rule, err, tc.want)
+		}
+	}
+}
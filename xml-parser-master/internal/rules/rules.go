@@ -0,0 +1,291 @@
+package rules
+
+import (
+	"embed"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"math"
+	"os"
+	"same-parser/internal/expr"
+	"strconv"
+	"strings"
+)
+
+//go:embed defaults/*.yml
+var defaultRules embed.FS
+
+// Ruleset: measInfo → montype 매핑 규칙 전체
+type Ruleset struct {
+	MeasInfos []MeasInfoRule `yaml:"meas_infos"`
+	Montypes  []MontypeRule  `yaml:"montypes"`
+	RuParam   *RuParamRule   `yaml:"ru_param"` // montype 에 ru_param 규칙이 없을 때 쓰는 기본 조회 키 규칙
+	Topology  *TopologyRule  `yaml:"topology"` // montype 에 topology 규칙이 없을 때 쓰는 기본 RU 위치 규칙
+
+	measInfoIdx map[string][]*MeasInfoRule
+	montypeIdx  map[string]*MontypeRule
+}
+
+// MeasInfoRule: measInfoId 하나를 어느 montype으로 모을지와 measType 이름 변환 규칙.
+// 같은 measInfoId 를 여러 montype 으로 나눠 쓸 수 있음(예: Ericsson PmGroup=EUtranCellFDD).
+type MeasInfoRule struct {
+	MeasInfoID    string            `yaml:"meas_info_id"`
+	Montype       string            `yaml:"montype"`
+	Counters      map[string]string `yaml:"counters"`       // measType → 필드명 (없는 measType은 버림)
+	GroupSegments int               `yaml:"group_segments"` // 0: measObjLdn 그대로, N: 앞 N개 세그먼트 단위로 합산
+}
+
+// MontypeRule: montype 하나의 적용 수집 주기와 내보낼 지표 목록
+type MontypeRule struct {
+	Name           string        `yaml:"name"`
+	Periods        []int         `yaml:"periods"`         // 적용할 수집 주기(분), 비우면 전체
+	ExcludePeriods []int         `yaml:"exclude_periods"` // 제외할 수집 주기(분)
+	Metrics        []MetricRule  `yaml:"metrics"`
+	RuParam        *RuParamRule  `yaml:"ru_param"` // 조회 키 규칙, 비우면 최상위 ru_param 규칙 (그것도 없으면 벤더 기본 키)
+	Topology       *TopologyRule `yaml:"topology"` // measObjLdn → RU 위치 항목 규칙, 비우면 최상위 topology 규칙
+}
+
+// MetricRule: ES 문서 한 건(data.field)으로 나가는 지표 정의
+type MetricRule struct {
+	Field     string `yaml:"field"`
+	Expr      string `yaml:"expr"`        // 필드명을 변수로 쓰는 산술식 (예: "AirMacULKB / 1024")
+	Round     *int   `yaml:"round"`       // 소수점 자리수, 비우면 반올림 안 함
+	Type      string `yaml:"type"`        // float(기본) | int
+	OnDivZero string `yaml:"on_div_zero"` // zero(기본): 0 으로 기록 | null: null 로 기록 | skip: 문서 생략
+
+	compiled *expr.Expr
+}
+
+// Default: 바이너리에 포함된 벤더/세대별 기본 규칙 (defaults/<vendor>_<generation>.yml)
+func Default(vendor, generation string) (*Ruleset, error) {
+	name := "defaults/" + strings.ToLower(vendor) + "_" + strings.ToLower(generation) + ".yml"
+	b, err := defaultRules.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("no default rules for %s %s (set parser.rules_file)", vendor, generation)
+	}
+	return parse(b)
+}
+
+// Load: 규칙 파일을 읽어 Ruleset 생성. 경로가 비어 있으면 벤더/세대별 기본 규칙 사용.
+func Load(path, vendor, generation string) (*Ruleset, error) {
+	if path == "" {
+		return Default(vendor, generation)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+	return parse(b)
+}
+
+func parse(b []byte) (*Ruleset, error) {
+	var rs Ruleset
+	if err := yaml.Unmarshal(b, &rs); err != nil {
+		return nil, fmt.Errorf("parse rules: %w", err)
+	}
+	if err := rs.build(); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// build: 규칙 검증 및 조회용 인덱스 생성
+func (rs *Ruleset) build() error {
+	if rs.RuParam != nil {
+		if err := rs.RuParam.compile(); err != nil {
+			return err
+		}
+	}
+	if rs.Topology != nil {
+		if err := rs.Topology.compile(); err != nil {
+			return err
+		}
+	}
+	rs.montypeIdx = make(map[string]*MontypeRule, len(rs.Montypes))
+	for i := range rs.Montypes {
+		mt := &rs.Montypes[i]
+		if mt.Name == "" {
+			return fmt.Errorf("montypes[%d]: name is required", i)
+		}
+		if _, dup := rs.montypeIdx[mt.Name]; dup {
+			return fmt.Errorf("montype %s: duplicated", mt.Name)
+		}
+		if mt.RuParam == nil {
+			mt.RuParam = rs.RuParam
+		} else if err := mt.RuParam.compile(); err != nil {
+			return fmt.Errorf("montype %s: %w", mt.Name, err)
+		}
+		if mt.Topology == nil {
+			mt.Topology = rs.Topology
+		} else if err := mt.Topology.compile(); err != nil {
+			return fmt.Errorf("montype %s: %w", mt.Name, err)
+		}
+		rs.montypeIdx[mt.Name] = mt
+	}
+
+	rs.measInfoIdx = make(map[string][]*MeasInfoRule, len(rs.MeasInfos))
+	for i := range rs.MeasInfos {
+		mi := &rs.MeasInfos[i]
+		if mi.MeasInfoID == "" {
+			return fmt.Errorf("meas_infos[%d]: meas_info_id is required", i)
+		}
+		if _, ok := rs.montypeIdx[mi.Montype]; !ok {
+			return fmt.Errorf("meas_info %s: unknown montype %q", mi.MeasInfoID, mi.Montype)
+		}
+		for _, other := range rs.measInfoIdx[mi.MeasInfoID] {
+			if other.Montype == mi.Montype {
+				return fmt.Errorf("meas_info %s: duplicated for montype %s", mi.MeasInfoID, mi.Montype)
+			}
+		}
+		if mi.GroupSegments < 0 {
+			return fmt.Errorf("meas_info %s: group_segments must be >= 0", mi.MeasInfoID)
+		}
+		rs.measInfoIdx[mi.MeasInfoID] = append(rs.measInfoIdx[mi.MeasInfoID], mi)
+	}
+
+	// 지표 식에서 참조하는 필드가 해당 montype의 measInfo 에서 만들어지는지 확인
+	fields := make(map[string]map[string]bool, len(rs.Montypes))
+	for _, mi := range rs.MeasInfos {
+		if fields[mi.Montype] == nil {
+			fields[mi.Montype] = make(map[string]bool)
+		}
+		for _, f := range mi.Counters {
+			fields[mi.Montype][f] = true
+		}
+	}
+	for i := range rs.Montypes {
+		mt := &rs.Montypes[i]
+		for j := range mt.Metrics {
+			m := &mt.Metrics[j]
+			if err := m.compile(); err != nil {
+				return fmt.Errorf("montype %s metrics[%d]: %w", mt.Name, j, err)
+			}
+			for _, id := range m.compiled.Idents() {
+				if !fields[mt.Name][id] {
+					return fmt.Errorf("montype %s metric %s: unknown field %q", mt.Name, m.Field, id)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// compile: 지표 정의 검증 및 식 컴파일
+func (m *MetricRule) compile() error {
+	if m.Field == "" {
+		return fmt.Errorf("field is required")
+	}
+	if m.Expr == "" {
+		return fmt.Errorf("%s: expr is required", m.Field)
+	}
+	switch m.Type {
+	case "", "float", "int":
+	default:
+		return fmt.Errorf("%s: unknown type %q", m.Field, m.Type)
+	}
+	switch m.OnDivZero {
+	case "", "zero", "null", "skip":
+	default:
+		return fmt.Errorf("%s: unknown on_div_zero %q", m.Field, m.OnDivZero)
+	}
+	e, err := expr.Compile(m.Expr)
+	if err != nil {
+		return fmt.Errorf("%s: %w", m.Field, err)
+	}
+	m.compiled = e
+	return nil
+}
+
+// MeasInfo: measInfoId에 해당하는 규칙 목록 조회 (montype 별로 하나씩)
+func (rs *Ruleset) MeasInfo(id string) []*MeasInfoRule {
+	return rs.measInfoIdx[id]
+}
+
+// Montype: montype 이름에 해당하는 규칙 조회
+func (rs *Ruleset) Montype(name string) (*MontypeRule, bool) {
+	r, ok := rs.montypeIdx[name]
+	return r, ok
+}
+
+// AppliesTo: 수집 주기(분)에 이 montype을 내보낼지 여부
+func (mt *MontypeRule) AppliesTo(period int) bool {
+	for _, p := range mt.ExcludePeriods {
+		if p == period {
+			return false
+		}
+	}
+	if len(mt.Periods) == 0 {
+		return true
+	}
+	for _, p := range mt.Periods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+// Rename: measType 이름을 필드명으로 변환, 규칙에 없으면 false
+func (mi *MeasInfoRule) Rename(measType string) (string, bool) {
+	f, ok := mi.Counters[measType]
+	return f, ok
+}
+
+// GroupKey: measObjLdn을 앞 GroupSegments개 세그먼트로 자른 합산 키, 세그먼트가 부족하면 false
+func (mi *MeasInfoRule) GroupKey(objLdn string) (string, bool) {
+	parts := strings.Split(objLdn, "/")
+	if len(parts) < mi.GroupSegments {
+		return "", false
+	}
+	return strings.Join(parts[:mi.GroupSegments], "/"), true
+}
+
+// Evaluate: 필드 값 맵으로 지표 값을 계산해 ES 문서의 data.result 값으로 반환.
+// 0으로 나누기 정책이 skip 이면 ok=false (문서 생략). 결과가 NaN/Inf(범위 초과)면 JSON 으로 기록할 수 없으므로 ok=false.
+func (m *MetricRule) Evaluate(values map[string]string) (interface{}, bool) {
+	v, err := m.compiled.Eval(func(name string) float64 {
+		return parseFloat(values[name])
+	})
+	if err != nil {
+		switch m.OnDivZero {
+		case "skip":
+			return nil, false
+		case "null":
+			return nil, true
+		default:
+			v = 0
+		}
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, false
+	}
+	if m.Round != nil {
+		v = roundTo(v, *m.Round)
+	}
+	if m.Type == "int" {
+		return int(v), true
+	}
+	return v, true
+}
+
+// parseFloat: 카운터 값을 숫자로 변환. 비었거나 숫자가 아니면(NaN, Inf 포함) 0
+func parseFloat(value string) float64 {
+	val := strings.TrimSpace(value)
+	if val == "" {
+		return 0.0
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0.0
+	}
+	return f
+}
+
+// roundTo: 소수점 places 자리까지 반올림 (0.5 는 0 에서 먼 쪽). 자리를 올리면 범위를 넘는 큰 값은 그대로 반환.
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	scaled := value * factor
+	if math.IsInf(scaled, 0) {
+		return value
+	}
+	return math.Round(scaled) / factor
+}
@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestRoundTo(t *testing.T) {
+	tests := []struct {
+		value  float64
+		places int
+		want   float64
+	}{
+		{1.25, 1, 1.3},
+		{2.5, 0, 3},
+		{-2.5, 0, -3}, // 예전 int64(v+0.5) 는 -2
+		{-1.234, 2, -1.23},
+		{-0.4, 0, 0},
+		{12.3456, 3, 12.346},
+		{99.995, 0, 100},
+		{1e300, 10, 1e300}, // 자리를 올리면 Inf 가 되는 값은 그대로
+		{float64(math.MaxInt64) * 4, 0, float64(math.MaxInt64) * 4}, // int64 범위 밖
+	}
+	for _, tt := range tests {
+		if got := roundTo(tt.value, tt.places); got != tt.want {
+			t.Errorf("roundTo(%v, %d) = %v, want %v", tt.value, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestParseFloat(t *testing.T) {
+	tests := map[string]float64{
+		"":       0,
+		" 12.5 ": 12.5,
+		"-3":     -3,
+		"abc":    0,
+		"NaN":    0,
+		"nan":    0,
+		"Inf":    0,
+		"-inf":   0,
+		"1e400":  0, // 범위 초과
+	}
+	for in, want := range tests {
+		if got := parseFloat(in); got != want {
+			t.Errorf("parseFloat(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+// metricRule: expr 하나로 컴파일한 지표
+func metricRule(t *testing.T, m MetricRule) *MetricRule {
+	t.Helper()
+	if err := m.compile(); err != nil {
+		t.Fatal(err)
+	}
+	return &m
+}
+
+// TestEvaluateFinite: NaN/Inf 카운터는 0 으로, Inf 결과는 문서 생략으로 처리해 json.Marshal 까지 가지 않아야 함
+func TestEvaluateFinite(t *testing.T) {
+	two := 2
+	tests := []struct {
+		name   string
+		rule   MetricRule
+		values map[string]string
+		want   interface{}
+		ok     bool
+	}{
+		{"nan counter", MetricRule{Field: "f", Expr: "a + b"}, map[string]string{"a": "NaN", "b": "1"}, 1.0, true},
+		{"inf counter", MetricRule{Field: "f", Expr: "a * 2"}, map[string]string{"a": "+Inf"}, 0.0, true},
+		{"overflow", MetricRule{Field: "f", Expr: "a * a"}, map[string]string{"a": "1e200"}, nil, false},
+		{"overflow rounded", MetricRule{Field: "f", Expr: "a * 10", Round: &two}, map[string]string{"a": "1.7e308"}, nil, false},
+		{"rounded", MetricRule{Field: "f", Expr: "a / 3", Round: &two}, map[string]string{"a": "-10"}, -3.33, true},
+		{"int", MetricRule{Field: "f", Expr: "a", Type: "int", Round: new(int)}, map[string]string{"a": "-2.5"}, -3, true},
+		{"div zero null", MetricRule{Field: "f", Expr: "a / b", OnDivZero: "null"}, map[string]string{"a": "1", "b": "0"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := metricRule(t, tt.rule).Evaluate(tt.values)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("Evaluate = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+			if ok {
+				if _, err := json.Marshal(got); err != nil {
+					t.Errorf("json.Marshal(%v): %v", got, err)
+				}
+			}
+		})
+	}
+}
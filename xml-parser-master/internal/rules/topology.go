@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"same-parser/internal/model"
+	"strconv"
+)
+
+// topologyGroups: topology 패턴에서 쓸 수 있는 그룹 이름 → 문서 topology 항목
+var topologyGroups = map[string]func(t *model.Topology) **int{
+	"board":   func(t *model.Topology) **int { return &t.BoardID },
+	"port":    func(t *model.Topology) **int { return &t.RUPort },
+	"cascade": func(t *model.Topology) **int { return &t.CascadeID },
+	"cell":    func(t *model.Topology) **int { return &t.CellNum },
+	"carrier": func(t *model.Topology) **int { return &t.Carrier },
+}
+
+// TopologyRule: measObjLdn(또는 합산 키)에서 RU 위치 항목을 읽는 정규식 목록.
+// 이름 있는 그룹 board, port, cascade, cell, carrier 를 정수로 읽으며, 여러 패턴이 맞으면 먼저 읽은 값 우선.
+// 매핑 조회 결과와 관계없이 문서 topology 로 나감.
+type TopologyRule struct {
+	Patterns []string `yaml:"patterns"`
+
+	compiled []*regexp.Regexp
+}
+
+// compile: 패턴 컴파일 및 그룹 이름 확인
+func (t *TopologyRule) compile() error {
+	t.compiled = make([]*regexp.Regexp, 0, len(t.Patterns))
+	for i, p := range t.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("topology patterns[%d]: %w", i, err)
+		}
+		groups := 0
+		for _, name := range re.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			if _, ok := topologyGroups[name]; !ok {
+				return fmt.Errorf("topology patterns[%d]: unknown group %q (board, port, cascade, cell, carrier)", i, name)
+			}
+			groups++
+		}
+		if groups == 0 {
+			return fmt.Errorf("topology patterns[%d]: no named group", i)
+		}
+		t.compiled = append(t.compiled, re)
+	}
+	return nil
+}
+
+// ParseTopology: measObjLdn 에서 RU 위치 항목 추출, 규칙이 없거나 읽은 항목이 없으면 nil
+func (mt *MontypeRule) ParseTopology(objLdn string) *model.Topology {
+	if mt.Topology == nil {
+		return nil
+	}
+	var topo model.Topology
+	found := false
+	for _, re := range mt.Topology.compiled {
+		m := re.FindStringSubmatch(objLdn)
+		if m == nil {
+			continue
+		}
+		for i, name := range re.SubexpNames() {
+			field, ok := topologyGroups[name]
+			if !ok || *field(&topo) != nil {
+				continue
+			}
+			n, err := strconv.Atoi(m[i])
+			if err != nil {
+				continue // 숫자가 아니거나 선택 그룹이 비어 있음
+			}
+			*field(&topo) = &n
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &topo
+}
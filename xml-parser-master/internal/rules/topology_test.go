@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"fmt"
+	"same-parser/internal/model"
+	"strings"
+	"testing"
+)
+
+// topoString: 비교용 "board=1 port=2" 형식 (nil 이면 빈 문자열)
+func topoString(t *model.Topology) string {
+	if t == nil {
+		return ""
+	}
+	var parts []string
+	for _, f := range []struct {
+		name string
+		v    *int
+	}{{"board", t.BoardID}, {"port", t.RUPort}, {"cascade", t.CascadeID}, {"cell", t.CellNum}, {"carrier", t.Carrier}} {
+		if f.v != nil {
+			parts = append(parts, fmt.Sprintf("%s=%d", f.name, *f.v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// TestSamsungLTETopology: 기본 Samsung LTE topology 패턴으로 measObjLdn(또는 합산 키)에서 읽는 RU 위치
+func TestSamsungLTETopology(t *testing.T) {
+	rs, err := Default("SAMSUNG", "LTE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		ldn  string
+		want string
+	}{
+		{"/UMP00/BID1/RuPort2/Cascade0", "board=1 port=2 cascade=0"},
+		{"/UMP00/BID1/RuPort2", "board=1 port=2"},
+		{"/ump00/bid10/ruport0/cascade3", "board=10 port=0 cascade=3"},
+		{"/UMP00/cNum3", "cell=3"},
+		{"/UMP00/cNum3/Carrier1", "cell=3 carrier=1"},
+		{"/UMP00/cNum1/Plmn0", "cell=1"},
+		{"DU001/UMP00/BID1/RuPort2/Cascade0/cNum4/Carrier0", "board=1 port=2 cascade=0 cell=4 carrier=0"},
+		{"/UMP00/BID1", ""},
+		{"/UMP00/cNumX", ""},
+		{"/UMP00/Other0", ""},
+		{"", ""},
+	}
+	for _, mt := range rs.Montypes {
+		for _, tc := range cases {
+			if got := topoString(mt.ParseTopology(tc.ldn)); got != tc.want {
+				t.Errorf("%s ParseTopology(%q) = %q, want %q", mt.Name, tc.ldn, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestParseTopology(t *testing.T) {
+	rs := mustParse(t, `
+meas_infos:
+  - {meas_info_id: A, montype: M, counters: {a: a}}
+  - {meas_info_id: B, montype: N, counters: {a: a}}
+montypes:
+  - name: M
+    metrics: [{field: a, expr: a}]
+  - name: N
+    topology:
+      patterns: ['c(?P<cell>\w+)']
+    metrics: [{field: a, expr: a}]
+topology:
+  patterns:
+    - 'first(?P<cell>\d+)'
+    - 'second(?P<cell>\d+)/p(?P<port>\d+)'
+`)
+	m, _ := rs.Montype("M")
+	n, _ := rs.Montype("N")
+	cases := []struct {
+		mt   *MontypeRule
+		ldn  string
+		want string
+	}{
+		// 여러 패턴이 같은 항목을 읽으면 먼저 읽은 값 우선, 다른 항목은 뒤 패턴에서도 채움
+		{m, "/first1/second2/p3", "port=3 cell=1"},
+		{m, "/second2/p3", "port=3 cell=2"},
+		{m, "/none", ""},
+		// montype 규칙이 최상위 규칙 대신 쓰이고, 숫자가 아닌 값은 건너뜀
+		{n, "/c7", "cell=7"},
+		{n, "/cX", ""},
+		{n, "/first1", ""},
+	}
+	for _, tc := range cases {
+		if got := topoString(tc.mt.ParseTopology(tc.ldn)); got != tc.want {
+			t.Errorf("%s ParseTopology(%q) = %q, want %q", tc.mt.Name, tc.ldn, got, tc.want)
+		}
+	}
+
+	// 규칙이 없으면 nil
+	none := mustParse(t, `
+meas_infos: [{meas_info_id: A, montype: M, counters: {a: a}}]
+montypes: [{name: M, metrics: [{field: a, expr: a}]}]
+`)
+	mt, _ := none.Montype("M")
+	if topo := mt.ParseTopology("/UMP00/BID1/RuPort2"); topo != nil {
+		t.Errorf("no rule: topology = %q", topoString(topo))
+	}
+}
+
+func TestTopologyCompileErrors(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    string
+	}{
+		{`'('`, "topology patterns[0]"},
+		{`'BID(\d+)'`, "no named group"},
+		{`'BID(?P<bid>\d+)'`, `unknown group "bid"`},
+	}
+	for _, tc := range cases {
+		_, err := parse([]byte(`
+meas_infos: [{meas_info_id: A, montype: M, counters: {a: a}}]
+montypes: [{name: M, metrics: [{field: a, expr: a}]}]
+topology:
+  patterns: [` + tc.pattern + `]`))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.pattern, err, tc.want)
+		}
+	}
+}
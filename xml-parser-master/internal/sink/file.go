@@ -0,0 +1,325 @@
+package sink
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"same-parser/internal/model"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// partSuffix: 기록 중인 파일 접미사. 회전/종료 시 떼어 내므로 다른 도구는 완성된 파일만 보게 됨.
+const partSuffix = ".part"
+
+// errFileClosed: Close 이후의 Write
+var errFileClosed = errors.New("closed")
+
+// FileConfig: NDJSON 파일 출력 설정
+type FileConfig struct {
+	Dir         string
+	Prefix      string        // 파일 이름 접두사 (기본 "kpi")
+	MaxBytes    int64         // 파일 하나의 최대 크기 (압축 전), 0 이면 크기 회전 안 함
+	RotateEvery time.Duration // 파일 회전 주기, 0 이면 시간 회전 안 함
+	Gzip        bool
+}
+
+// File: 문서를 한 줄에 하나씩 JSON 으로 기록하는 회전 파일 출력.
+// 파일 이름: <prefix>-YYYYMMDD-HHMMSS-<순번>.ndjson[.gz]
+// 성공 응답(acker.Succeeded)은 버퍼가 디스크에 flush+fsync 되거나 파일이 회전(닫기)된 뒤에 보냄.
+type File struct {
+	logger *logrus.Logger
+	cfg    FileConfig
+	acker  Acker // nil 가능
+
+	mu     sync.Mutex
+	f      *os.File
+	gz     *gzip.Writer
+	bw     *bufio.Writer
+	path   string // 기록 중인 파일의 최종 경로 (.part 제외)
+	opened time.Time
+	size   int64
+	seq    int
+
+	unacked []string // 버퍼에 썼지만 아직 flush/회전되지 않은 문서의 원본 파일
+
+	written   atomic.Uint64
+	succeeded atomic.Uint64
+	failed    atomic.Uint64
+
+	closed bool // Close 이후 (종료 시간 초과로 출력 워커가 아직 Write 하는 경우)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewFile: 파일 출력 생성. 이전 실행에서 남은 .part 파일은 완료 이름으로 바꿈.
+// acker 가 nil 이 아니면 기록 결과를 원본 파일 기준으로 알림.
+func NewFile(logger *logrus.Logger, cfg FileConfig, acker Acker) (*File, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("file sink: dir is empty")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "kpi"
+	}
+	if err := os.MkdirAll(cfg.Dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("file sink dir: %w", err)
+	}
+	if err := recoverParts(logger, cfg.Dir); err != nil {
+		return nil, err
+	}
+
+	s := &File{
+		logger: logger,
+		cfg:    cfg,
+		acker:  acker,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.rotateLoop()
+	return s, nil
+}
+
+func (s *File) Name() string {
+	return "file"
+}
+
+func (s *File) Write(_ context.Context, doc model.ElasticDocument) error {
+	source := ""
+	if doc.SourceFile != nil {
+		source = *doc.SourceFile
+	}
+	s.written.Add(1)
+	if s.acker != nil {
+		s.acker.Added(source)
+	}
+
+	// 성공 응답은 flush/회전 때 settle 에서
+	if err := s.write(doc, source); err != nil {
+		s.failed.Add(1)
+		if s.acker != nil {
+			s.acker.Failed(source, err.Error())
+		}
+		return fmt.Errorf("file sink: %w", err)
+	}
+	return nil
+}
+
+func (s *File) write(doc model.ElasticDocument, source string) error {
+	line, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errFileClosed
+	}
+	if s.f != nil && s.due(int64(len(line))) {
+		if err := s.closeFile(); err != nil {
+			return err
+		}
+	}
+	if s.f == nil {
+		if err := s.openFile(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.bw.Write(line); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	s.size += int64(len(line))
+	s.unacked = append(s.unacked, source)
+	return nil
+}
+
+func (s *File) Flush(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush()
+}
+
+func (s *File) Close(context.Context) error {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.closeFile()
+}
+
+func (s *File) Stats() Stats {
+	return Stats{
+		Name:      s.Name(),
+		Written:   s.written.Load(),
+		Succeeded: s.succeeded.Load(),
+		Failed:    s.failed.Load(),
+	}
+}
+
+// due: 다음 줄(n 바이트)을 쓰기 전에 회전해야 하는지 (s.mu 보유 상태)
+func (s *File) due(n int64) bool {
+	if s.cfg.MaxBytes > 0 && s.size > 0 && s.size+n > s.cfg.MaxBytes {
+		return true
+	}
+	return s.cfg.RotateEvery > 0 && time.Since(s.opened) >= s.cfg.RotateEvery
+}
+
+// rotateLoop: 문서가 뜸해도 회전 주기가 지나면 파일을 닫고, 주기적으로 flush
+func (s *File) rotateLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			var err error
+			if s.f != nil && s.cfg.RotateEvery > 0 && time.Since(s.opened) >= s.cfg.RotateEvery {
+				err = s.closeFile()
+			} else {
+				err = s.flush()
+			}
+			s.mu.Unlock()
+			if err != nil {
+				s.logger.Errorf("file sink: %v", err)
+			}
+		}
+	}
+}
+
+func (s *File) openFile() error {
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s-%04d.ndjson", s.cfg.Prefix, now.Format("20060102-150405"), s.seq)
+	if s.cfg.Gzip {
+		name += ".gz"
+	}
+	s.seq = (s.seq + 1) % 10000
+
+	path := filepath.Join(s.cfg.Dir, name)
+	f, err := os.OpenFile(path+partSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	var w io.Writer = f
+	s.gz = nil
+	if s.cfg.Gzip {
+		s.gz = gzip.NewWriter(f)
+		w = s.gz
+	}
+	s.f, s.bw, s.path, s.opened, s.size = f, bufio.NewWriterSize(w, 256<<10), path, now, 0
+	return nil
+}
+
+// flush: 버퍼를 디스크까지 내보내고(fsync) 그동안 쓴 문서에 응답 (s.mu 보유 상태)
+func (s *File) flush() error {
+	if s.f == nil || len(s.unacked) == 0 {
+		return nil
+	}
+	err := s.sync()
+	s.settle(err)
+	return err
+}
+
+// sync: 버퍼와 gzip 블록을 내보내고 fsync
+func (s *File) sync() error {
+	if err := s.bw.Flush(); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+	if s.gz != nil {
+		if err := s.gz.Flush(); err != nil {
+			return fmt.Errorf("flush gzip: %w", err)
+		}
+	}
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("fsync: %w", err)
+	}
+	return nil
+}
+
+// settle: flush/회전이 끝난 문서의 기록 결과 응답 (s.mu 보유 상태)
+func (s *File) settle(err error) {
+	for _, source := range s.unacked {
+		if err != nil {
+			s.failed.Add(1)
+			if s.acker != nil {
+				s.acker.Failed(source, err.Error())
+			}
+			continue
+		}
+		s.succeeded.Add(1)
+		if s.acker != nil {
+			s.acker.Succeeded(source)
+		}
+	}
+	s.unacked = s.unacked[:0]
+}
+
+// closeFile: 기록 중인 파일을 닫고 .part 를 떼어 낸 뒤 그 파일에 쓴 문서에 응답 (s.mu 보유 상태)
+func (s *File) closeFile() error {
+	if s.f == nil {
+		return nil
+	}
+	err := s.finish()
+	s.settle(err)
+	return err
+}
+
+// finish: 버퍼 flush, fsync, 닫기 후 .part 를 떼어 냄
+func (s *File) finish() error {
+	err := s.bw.Flush()
+	if s.gz != nil {
+		if gzErr := s.gz.Close(); err == nil {
+			err = gzErr
+		}
+	}
+	if syncErr := s.f.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := s.f.Close(); err == nil {
+		err = closeErr
+	}
+	path := s.path
+	s.f, s.gz, s.bw, s.path = nil, nil, nil, ""
+	if err != nil {
+		return fmt.Errorf("close %s: %w", path, err)
+	}
+	if err := os.Rename(path+partSuffix, path); err != nil {
+		return fmt.Errorf("rename %s: %w", path, err)
+	}
+	return nil
+}
+
+// recoverParts: 비정상 종료로 남은 .part 파일을 완료 이름으로 변경 (gzip 이면 끝부분이 잘려 있을 수 있음)
+func recoverParts(logger *logrus.Logger, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read file sink dir: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), partSuffix) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if err := os.Rename(path, strings.TrimSuffix(path, partSuffix)); err != nil {
+			return fmt.Errorf("recover %s: %w", path, err)
+		}
+		logger.Warnf("이전 실행에서 닫히지 않은 파일 복구: %s", strings.TrimSuffix(e.Name(), partSuffix))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package sink
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"same-parser/internal/model"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// countAcker: 원본 파일별 응답 횟수
+type countAcker struct {
+	mu        sync.Mutex
+	added     int
+	succeeded int
+	failed    int
+}
+
+func (a *countAcker) Added(string) {
+	a.mu.Lock()
+	a.added++
+	a.mu.Unlock()
+}
+
+func (a *countAcker) Succeeded(string) {
+	a.mu.Lock()
+	a.succeeded++
+	a.mu.Unlock()
+}
+
+func (a *countAcker) Failed(string, string) {
+	a.mu.Lock()
+	a.failed++
+	a.mu.Unlock()
+}
+
+func (a *countAcker) get() (int, int, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.added, a.succeeded, a.failed
+}
+
+func fileDoc(field string) model.ElasticDocument {
+	source := "/scan/A.xml"
+	return model.ElasticDocument{Data: model.Data{Field: field}, SourceFile: &source}
+}
+
+// TestFileAckAfterFlush: 성공 응답은 버퍼에 쓴 시점이 아니라 flush 또는 회전 뒤에 와야 함
+func TestFileAckAfterFlush(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	dir := t.TempDir()
+	acker := &countAcker{}
+	s, err := NewFile(logger, FileConfig{Dir: dir, MaxBytes: 1 << 20}, acker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, f := range []string{"a", "b"} {
+		if err := s.Write(ctx, fileDoc(f)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if added, succeeded, _ := acker.get(); added != 2 || succeeded != 0 {
+		t.Fatalf("before flush: added=%d succeeded=%d, want 2/0", added, succeeded)
+	}
+
+	if err := s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, succeeded, _ := acker.get(); succeeded != 2 {
+		t.Fatalf("after flush: succeeded=%d, want 2", succeeded)
+	}
+	// flush 된 내용은 .part 파일에 있어야 함
+	parts, _ := filepath.Glob(filepath.Join(dir, "*"+partSuffix))
+	if len(parts) != 1 {
+		t.Fatalf("part files = %v", parts)
+	}
+	// source_file 은 응답 집계용이므로 출력 문서에 없어야 함
+	if b, _ := os.ReadFile(parts[0]); strings.Count(string(b), "\n") != 2 || strings.Contains(string(b), "source_file") {
+		t.Errorf("part content = %q", b)
+	}
+
+	// 닫기(회전)에서 남은 문서 응답
+	if err := s.Write(ctx, fileDoc("c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if added, succeeded, failed := acker.get(); added != 3 || succeeded != 3 || failed != 0 {
+		t.Errorf("after close: added=%d succeeded=%d failed=%d", added, succeeded, failed)
+	}
+	if st := s.Stats(); st.Written != 3 || st.Succeeded != 3 {
+		t.Errorf("stats = %+v", st)
+	}
+
+	// 종료 후 Write 는 거절
+	if err := s.Write(ctx, fileDoc("d")); err == nil {
+		t.Error("Write after Close succeeded")
+	}
+	if _, _, failed := acker.get(); failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+}
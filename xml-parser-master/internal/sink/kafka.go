@@ -0,0 +1,280 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"same-parser/internal/model"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KafkaConfig: Kafka 출력 설정
+type KafkaConfig struct {
+	Brokers         []string
+	Topic           string        // 토픽 템플릿, {montype} {generation} {equip_id} 치환 (예: pm.lte.{montype})
+	Key             string        // 파티션 키: ru_param | cell_id | none (기본 ru_param)
+	BatchSize       int           // 배치 최대 메시지 수 (기본 kafka-go 100)
+	BatchBytes      int64         // 배치 최대 크기 (기본 kafka-go 1MB)
+	Linger          time.Duration // 배치가 덜 차도 보내는 최대 대기 시간 (기본 kafka-go 1초)
+	Compression     string        // none | gzip | snappy | lz4 | zstd
+	RequiredAcks    string        // none | one | all (기본 all)
+	MaxAttempts     int           // 전송 재시도 횟수 (기본 kafka-go 10)
+	AutoCreateTopic bool
+}
+
+// Kafka: 문서를 JSON 메시지로 Kafka 에 발행하는 출력.
+// 비동기 전송이며, 브로커 응답(성공/실패)은 Completion 콜백에서 집계하고 실패는 배치마다 로그로 남김.
+type Kafka struct {
+	logger *logrus.Logger
+	topic  string
+	key    string
+	acker  Acker // nil 가능
+	writer *kafka.Writer
+
+	written   atomic.Uint64
+	succeeded atomic.Uint64
+	failed    atomic.Uint64
+
+	mu      sync.Mutex
+	byTopic map[string]*topicStats
+
+	ackMu    sync.RWMutex
+	detached bool // Close 이후에는 acker 에 응답하지 않음 (ctx 만료 후 백그라운드에서 끝나는 배치 포함)
+}
+
+type topicStats struct {
+	succeeded uint64
+	failed    uint64
+}
+
+// NewKafka: Kafka 출력 생성. 브로커 연결은 첫 전송 때 이루어짐.
+// acker 가 nil 이 아니면 브로커 응답을 원본 파일 기준으로 알림.
+func NewKafka(logger *logrus.Logger, cfg KafkaConfig, acker Acker) (*Kafka, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka sink: brokers is empty")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("kafka sink: topic is empty")
+	}
+	key := strings.ToLower(cfg.Key)
+	switch key {
+	case "":
+		key = "ru_param"
+	case "ru_param", "cell_id", "none":
+	default:
+		return nil, fmt.Errorf("kafka sink: unknown key %q (ru_param, cell_id, none)", cfg.Key)
+	}
+
+	var compression kafka.Compression
+	if cfg.Compression != "" {
+		if err := compression.UnmarshalText([]byte(strings.ToLower(cfg.Compression))); err != nil {
+			return nil, fmt.Errorf("kafka sink compression: %w", err)
+		}
+	}
+	acks := kafka.RequireAll
+	if cfg.RequiredAcks != "" {
+		if err := acks.UnmarshalText([]byte(strings.ToLower(cfg.RequiredAcks))); err != nil {
+			return nil, fmt.Errorf("kafka sink required_acks: %w", err)
+		}
+	}
+
+	s := &Kafka{
+		logger:  logger,
+		topic:   cfg.Topic,
+		key:     key,
+		acker:   acker,
+		byTopic: make(map[string]*topicStats),
+	}
+	s.writer = &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Brokers...),
+		Balancer:               &kafka.Hash{}, // 같은 키는 같은 파티션, 키가 없으면 라운드로빈
+		MaxAttempts:            cfg.MaxAttempts,
+		BatchSize:              cfg.BatchSize,
+		BatchBytes:             cfg.BatchBytes,
+		BatchTimeout:           cfg.Linger,
+		RequiredAcks:           acks,
+		Compression:            compression,
+		Async:                  true,
+		Completion:             s.complete,
+		AllowAutoTopicCreation: cfg.AutoCreateTopic,
+		ErrorLogger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
+			logger.Warnf("kafka: "+msg, args...)
+		}),
+	}
+	return s, nil
+}
+
+func (s *Kafka) Name() string {
+	return "kafka"
+}
+
+// Write: 메시지를 전송 큐에 넣음. 결과는 complete 에서 처리.
+func (s *Kafka) Write(ctx context.Context, doc model.ElasticDocument) error {
+	source := ""
+	if doc.SourceFile != nil {
+		source = *doc.SourceFile
+	}
+	s.written.Add(1)
+	s.ack(func(a Acker) { a.Added(source) })
+
+	topic := topicFor(s.topic, doc)
+	value, err := json.Marshal(doc)
+	if err != nil {
+		s.fail(topic, source, err.Error())
+		return fmt.Errorf("kafka sink marshal: %s %w", source, err)
+	}
+	msg := kafka.Message{
+		Topic:      topic,
+		Key:        s.keyOf(doc),
+		Value:      value,
+		WriterData: source,
+	}
+	if err := s.writer.WriteMessages(ctx, msg); err != nil {
+		s.fail(topic, source, err.Error())
+		return fmt.Errorf("kafka sink: topic=%s %w", topic, err)
+	}
+	return nil
+}
+
+// Flush: kafka-go Writer 는 명시적 flush 가 없으므로 batch_size/linger 에 맡김
+func (s *Kafka) Flush(context.Context) error {
+	return nil
+}
+
+// Close: 남은 배치를 보내고 브로커 응답을 모두 받을 때까지 대기.
+// ctx 가 먼저 만료되면 writer.Close 는 백그라운드에서 계속되지만, 반환 전에 acker 를 분리해
+// 종료 집계 뒤에 도착한 응답이 Tracker 에 반영되지 않음 (응답을 못 받은 파일은 미완료로 남음).
+func (s *Kafka) Close(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- s.writer.Close() }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	s.ackMu.Lock()
+	s.detached = true
+	s.ackMu.Unlock()
+
+	s.mu.Lock()
+	for topic, st := range s.byTopic {
+		s.logger.Infof("kafka 토픽 통계 [%s]: succeeded=%d failed=%d", topic, st.succeeded, st.failed)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("kafka sink close: %w", err)
+	}
+	return nil
+}
+
+func (s *Kafka) Stats() Stats {
+	return Stats{
+		Name:      s.Name(),
+		Written:   s.written.Load(),
+		Succeeded: s.succeeded.Load(),
+		Failed:    s.failed.Load(),
+	}
+}
+
+// complete: 배치(같은 토픽/파티션) 단위 브로커 응답
+func (s *Kafka) complete(messages []kafka.Message, err error) {
+	if len(messages) == 0 {
+		return
+	}
+	topic := messages[0].Topic
+	if err != nil {
+		s.logger.Errorf("kafka 전송 실패 topic=%s partition=%d count=%d: %v", topic, messages[0].Partition, len(messages), err)
+		for _, m := range messages {
+			source, _ := m.WriterData.(string)
+			s.fail(topic, source, err.Error())
+		}
+		return
+	}
+
+	s.succeeded.Add(uint64(len(messages)))
+	s.count(topic, uint64(len(messages)), 0)
+	s.ack(func(a Acker) {
+		for _, m := range messages {
+			source, _ := m.WriterData.(string)
+			a.Succeeded(source)
+		}
+	})
+}
+
+// fail: 실패 집계 (로그는 호출자가 남김)
+func (s *Kafka) fail(topic, source, reason string) {
+	s.failed.Add(1)
+	s.count(topic, 0, 1)
+	s.ack(func(a Acker) { a.Failed(source, reason) })
+}
+
+// ack: acker 가 있고 Close 로 분리되지 않았으면 fn 호출 (Close 는 진행 중인 fn 이 끝날 때까지 대기)
+func (s *Kafka) ack(fn func(Acker)) {
+	s.ackMu.RLock()
+	defer s.ackMu.RUnlock()
+	if s.acker != nil && !s.detached {
+		fn(s.acker)
+	}
+}
+
+func (s *Kafka) count(topic string, succeeded, failed uint64) {
+	s.mu.Lock()
+	st, ok := s.byTopic[topic]
+	if !ok {
+		st = &topicStats{}
+		s.byTopic[topic] = st
+	}
+	st.succeeded += succeeded
+	st.failed += failed
+	s.mu.Unlock()
+}
+
+// keyOf: 파티션 키 (값이 없으면 nil → 라운드로빈)
+func (s *Kafka) keyOf(doc model.ElasticDocument) []byte {
+	var v *string
+	switch s.key {
+	case "ru_param":
+		v = doc.RuParam
+	case "cell_id":
+		v = doc.CellId
+	}
+	if v == nil || *v == "" {
+		return nil
+	}
+	return []byte(*v)
+}
+
+// topicFor: 토픽 템플릿의 {montype} {generation} {equip_id} 를 문서 값으로 치환.
+// 값은 소문자로 바꾸고 Kafka 토픽에 쓸 수 없는 문자는 '_' 로 바꿈. 값이 없으면 unknown.
+func topicFor(template string, doc model.ElasticDocument) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+	return strings.NewReplacer(
+		"{montype}", topicPart(doc.MontypeName),
+		"{generation}", topicPart(doc.Generation),
+		"{equip_id}", topicPart(doc.EquipID),
+	).Replace(template)
+}
+
+func topicPart(v *string) string {
+	if v == nil || *v == "" {
+		return "unknown"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '_'
+	}, *v)
+}
@@ -0,0 +1,287 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+	"github.com/segmentio/kafka-go/protocol/produce"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net"
+	"same-parser/internal/model"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeKafka: 파티션 하나짜리 토픽을 흉내 내는 kafka-go Transport.
+// fail 에 있는 토픽의 produce 는 해당 오류 코드로 응답하고, block 이 있으면 닫힐 때까지 produce 응답을 미룸.
+type fakeKafka struct {
+	fail  map[string]kafka.Error
+	block chan struct{}
+
+	mu       sync.Mutex
+	produced map[string][]fakeRecord // 토픽 → 성공한 레코드
+}
+
+type fakeRecord struct {
+	key   string
+	value string
+}
+
+func (f *fakeKafka) RoundTrip(ctx context.Context, _ net.Addr, req kafka.Request) (kafka.Response, error) {
+	switch r := req.(type) {
+	case *metadata.Request:
+		res := &metadata.Response{Brokers: []metadata.ResponseBroker{{NodeID: 1, Host: "fake", Port: 9092}}}
+		for _, name := range r.TopicNames {
+			res.Topics = append(res.Topics, metadata.ResponseTopic{
+				Name:       name,
+				Partitions: []metadata.ResponsePartition{{PartitionIndex: 0, LeaderID: 1}},
+			})
+		}
+		return res, nil
+	case *produce.Request:
+		if f.block != nil {
+			select {
+			case <-f.block:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		topic := r.Topics[0].Topic
+		res := &produce.Response{Topics: []produce.ResponseTopic{{
+			Topic:      topic,
+			Partitions: []produce.ResponsePartition{{Partition: 0, ErrorCode: int16(f.fail[topic])}},
+		}}}
+		if f.fail[topic] != 0 {
+			return res, nil
+		}
+		records := r.Topics[0].Partitions[0].RecordSet.Records
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.produced == nil {
+			f.produced = make(map[string][]fakeRecord)
+		}
+		for {
+			rec, err := records.ReadRecord()
+			if err != nil {
+				break
+			}
+			var fr fakeRecord
+			if rec.Key != nil {
+				b, _ := io.ReadAll(rec.Key)
+				fr.key = string(b)
+			}
+			b, _ := io.ReadAll(rec.Value)
+			fr.value = string(b)
+			f.produced[topic] = append(f.produced[topic], fr)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unexpected request %T", req)
+}
+
+func (f *fakeKafka) records(topic string) []fakeRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]fakeRecord(nil), f.produced[topic]...)
+}
+
+// fileAcker: 원본 파일별 응답 기록
+type fileAcker struct {
+	mu        sync.Mutex
+	added     map[string]int
+	succeeded map[string]int
+	failed    map[string][]string
+}
+
+func newFileAcker() *fileAcker {
+	return &fileAcker{added: map[string]int{}, succeeded: map[string]int{}, failed: map[string][]string{}}
+}
+
+func (a *fileAcker) Added(file string) {
+	a.mu.Lock()
+	a.added[file]++
+	a.mu.Unlock()
+}
+
+func (a *fileAcker) Succeeded(file string) {
+	a.mu.Lock()
+	a.succeeded[file]++
+	a.mu.Unlock()
+}
+
+func (a *fileAcker) Failed(file, reason string) {
+	a.mu.Lock()
+	a.failed[file] = append(a.failed[file], reason)
+	a.mu.Unlock()
+}
+
+func (a *fileAcker) counts(file string) (int, int, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.added[file], a.succeeded[file], len(a.failed[file])
+}
+
+func newTestKafka(t *testing.T, cfg KafkaConfig, transport *fakeKafka, acker Acker) *Kafka {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg.Brokers = []string{"fake:9092"}
+	cfg.Linger = 10 * time.Millisecond
+	cfg.MaxAttempts = 1
+	s, err := NewKafka(logger, cfg, acker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.writer.Transport = transport
+	return s
+}
+
+func kafkaDoc(source, montype, ruParam, cellID string) model.ElasticDocument {
+	str := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+	gen := "LTE"
+	return model.ElasticDocument{
+		MontypeName: str(montype),
+		RuParam:     str(ruParam),
+		CellId:      str(cellID),
+		Generation:  &gen,
+		Data:        model.Data{Field: "F", Result: 1},
+		SourceFile:  str(source),
+	}
+}
+
+func TestTopicFor(t *testing.T) {
+	str := func(s string) *string { return &s }
+	doc := model.ElasticDocument{MontypeName: str("PRB"), Generation: str("NR"), EquipID: str("DU 001/A")}
+	cases := []struct {
+		template string
+		doc      model.ElasticDocument
+		want     string
+	}{
+		{"pm.static", doc, "pm.static"},
+		{"pm.{generation}.{montype}", doc, "pm.nr.prb"},
+		{"pm.{equip_id}", doc, "pm.du_001_a"},
+		{"pm.{montype}.{montype}", doc, "pm.prb.prb"},
+		{"pm.{generation}.{montype}", model.ElasticDocument{MontypeName: str("")}, "pm.unknown.unknown"},
+		{"pm.{other}", doc, "pm.{other}"},
+	}
+	for _, tc := range cases {
+		if got := topicFor(tc.template, tc.doc); got != tc.want {
+			t.Errorf("topicFor(%q) = %q, want %q", tc.template, got, tc.want)
+		}
+	}
+}
+
+func TestKafkaKeyOf(t *testing.T) {
+	cases := []struct {
+		key  string
+		doc  model.ElasticDocument
+		want []byte
+	}{
+		{"", kafkaDoc("", "PRB", "DU1/RU1", "C1"), []byte("DU1/RU1")},
+		{"RU_PARAM", kafkaDoc("", "PRB", "DU1/RU1", "C1"), []byte("DU1/RU1")},
+		{"cell_id", kafkaDoc("", "PRB", "DU1/RU1", "C1"), []byte("C1")},
+		{"cell_id", kafkaDoc("", "PRB", "DU1/RU1", ""), nil},
+		{"none", kafkaDoc("", "PRB", "DU1/RU1", "C1"), nil},
+	}
+	for _, tc := range cases {
+		s := newTestKafka(t, KafkaConfig{Topic: "pm", Key: tc.key}, &fakeKafka{}, nil)
+		if got := s.keyOf(tc.doc); string(got) != string(tc.want) || (got == nil) != (tc.want == nil) {
+			t.Errorf("key %q: keyOf = %q, want %q", tc.key, got, tc.want)
+		}
+	}
+	if _, err := NewKafka(logrus.New(), KafkaConfig{Brokers: []string{"fake:9092"}, Topic: "pm", Key: "equip_id"}, nil); err == nil {
+		t.Error("unknown key accepted")
+	}
+}
+
+// TestKafkaAcks: 토픽별 배치 응답이 원본 파일 기준으로 acker 에 전달되어야 함.
+// 한 파일의 문서가 여러 토픽으로 나뉘고 그중 한 토픽만 실패하면 그 배치 문서만 실패로 응답.
+func TestKafkaAcks(t *testing.T) {
+	transport := &fakeKafka{fail: map[string]kafka.Error{"pm.lte.rrc": kafka.MessageSizeTooLarge}}
+	acker := newFileAcker()
+	s := newTestKafka(t, KafkaConfig{Topic: "pm.{generation}.{montype}"}, transport, acker)
+
+	ctx := context.Background()
+	docs := []model.ElasticDocument{
+		kafkaDoc("/scan/A.xml", "PRB", "DU1/RU1", ""),
+		kafkaDoc("/scan/A.xml", "PRB", "DU1/RU2", ""),
+		kafkaDoc("/scan/A.xml", "RRC", "DU1/cNum1", ""),
+		kafkaDoc("/scan/B.xml", "PRB", "DU2/RU1", ""),
+	}
+	for _, doc := range docs {
+		if err := s.Write(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if added, succeeded, failed := acker.counts("/scan/A.xml"); added != 3 || succeeded != 2 || failed != 1 {
+		t.Errorf("A.xml: added=%d succeeded=%d failed=%d, want 3/2/1", added, succeeded, failed)
+	}
+	if added, succeeded, failed := acker.counts("/scan/B.xml"); added != 1 || succeeded != 1 || failed != 0 {
+		t.Errorf("B.xml: added=%d succeeded=%d failed=%d, want 1/1/0", added, succeeded, failed)
+	}
+	if st := s.Stats(); st.Written != 4 || st.Succeeded != 3 || st.Failed != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+
+	recs := transport.records("pm.lte.prb")
+	var keys []string
+	for _, r := range recs {
+		keys = append(keys, r.key)
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(r.value), &doc); err != nil {
+			t.Errorf("value %q: %v", r.value, err)
+		}
+	}
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[DU1/RU1 DU1/RU2 DU2/RU1]" {
+		t.Errorf("pm.lte.prb keys = %v", keys)
+	}
+	if recs := transport.records("pm.lte.rrc"); len(recs) != 0 {
+		t.Errorf("pm.lte.rrc records = %v", recs)
+	}
+}
+
+// TestKafkaCloseExpired: Close 제한 시간이 지난 뒤 백그라운드에서 끝난 배치는 acker 에 응답하지 않아야 함
+func TestKafkaCloseExpired(t *testing.T) {
+	transport := &fakeKafka{block: make(chan struct{})}
+	acker := newFileAcker()
+	s := newTestKafka(t, KafkaConfig{Topic: "pm"}, transport, acker)
+
+	if err := s.Write(context.Background(), kafkaDoc("/scan/A.xml", "PRB", "DU1/RU1", "")); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close err = %v, want deadline exceeded", err)
+	}
+
+	// Close 반환 후 브로커 응답 도착
+	close(transport.block)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(transport.records("pm")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("batch never produced")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond) // Completion 콜백 대기
+	if added, succeeded, failed := acker.counts("/scan/A.xml"); added != 1 || succeeded != 0 || failed != 0 {
+		t.Errorf("after Close: added=%d succeeded=%d failed=%d, want 1/0/0", added, succeeded, failed)
+	}
+}
@@ -0,0 +1,117 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"same-parser/internal/model"
+)
+
+// Sink: 파싱된 KPI 문서 출력 대상 (Elasticsearch, NDJSON 파일 등)
+type Sink interface {
+	// Name: 로그/통계용 이름
+	Name() string
+	// Write: 문서 하나 기록. 비동기 구현은 큐에 넣고 바로 반환할 수 있음.
+	Write(ctx context.Context, doc model.ElasticDocument) error
+	// Flush: 버퍼에 있는 문서를 내보냄
+	Flush(ctx context.Context) error
+	// Close: 남은 문서를 내보내고 종료. 이후 Write 호출 금지.
+	Close(ctx context.Context) error
+	// Stats: 누적 통계
+	Stats() Stats
+}
+
+// Stats: 출력 대상별 누적 통계
+type Stats struct {
+	Name      string `json:"name"`
+	Written   uint64 `json:"written"`   // Write 로 받은 문서 수
+	Succeeded uint64 `json:"succeeded"` // 기록(색인) 확인된 문서 수
+	Failed    uint64 `json:"failed"`
+}
+
+// Acker: 문서의 원본 파일(SourceFile) 기준 기록 결과를 받는 쪽 (es.Tracker)
+type Acker interface {
+	Added(file string)
+	Succeeded(file string)
+	Failed(file, reason string)
+}
+
+// Multi: 여러 출력 대상에 같은 문서를 기록
+type Multi []Sink
+
+func (m Multi) Name() string {
+	return "multi"
+}
+
+// Write: 모든 출력 대상에 기록. 스풀에 되돌린 문서(RetrySink)는 그 출력 대상에만 기록.
+func (m Multi) Write(ctx context.Context, doc model.ElasticDocument) error {
+	var errs []error
+	for _, s := range m {
+		if doc.RetrySink != "" && doc.RetrySink != s.Name() {
+			continue
+		}
+		if err := s.Write(ctx, doc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m Multi) Flush(ctx context.Context) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m Multi) Close(ctx context.Context) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats: 하위 출력 대상 합계 (개별 통계는 Each 사용)
+func (m Multi) Stats() Stats {
+	total := Stats{Name: m.Name()}
+	for _, s := range m {
+		st := s.Stats()
+		total.Written += st.Written
+		total.Succeeded += st.Succeeded
+		total.Failed += st.Failed
+	}
+	return total
+}
+
+// Each: s 가 Multi 면 하위 출력 대상마다, 아니면 s 에 대해 fn 호출
+func Each(s Sink, fn func(Sink)) {
+	if m, ok := s.(Multi); ok {
+		for _, sub := range m {
+			Each(sub, fn)
+		}
+		return
+	}
+	fn(s)
+}
+
+// Start: docChan 의 문서를 s 에 기록하는 고루틴 실행.
+// docChan 이 닫히고 모든 문서를 넘기면 반환 채널이 닫힘 (이후 s.Close 로 flush).
+func Start(logger *logrus.Logger, s Sink, docChan <-chan model.ElasticDocument) <-chan struct{} {
+	ctx := context.Background()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for doc := range docChan {
+			if err := s.Write(ctx, doc); err != nil {
+				logger.Errorf("출력 실패: %v", err)
+			}
+		}
+	}()
+	return done
+}
@@ -0,0 +1,456 @@
+package spool
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"same-parser/internal/model"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	segPrefix = "seg-"
+	segSuffix = ".ndjson"
+
+	defaultSegmentBytes = 64 << 20
+
+	minPause = time.Second // 출력 실패 후 첫 전달 중지 시간
+	maxPause = time.Minute // 연속 실패 시 늘어나는 전달 중지 시간 상한
+)
+
+// Stats: 스풀 적체 현황
+type Stats struct {
+	Segments int   `json:"segments"` // 디스크에 남은 세그먼트 수 (기록 중인 것 포함)
+	Docs     int64 `json:"docs"`     // 디스크에 남은 문서 수
+	Bytes    int64 `json:"bytes"`    // 디스크에 남은 바이트
+}
+
+// Spool: 파서(in)와 벌크 인덱서(out) 사이의 디스크 세그먼트 스풀.
+// out 에 여유가 있고 스풀이 비어 있으면 메모리로 바로 넘기고, 그렇지 않으면(ES 장애 등)
+// 세그먼트 파일(seg-<순번>.ndjson)에 순서대로 기록한 뒤 out 이 풀리면 오래된 세그먼트부터 전달.
+// 출력이 실패를 알리면(Backoff) 채널 여유와 관계없이 전달을 멈추고 새 문서도 세그먼트에 쌓으며,
+// 실패한 문서는 Requeue 로 세그먼트 끝에 다시 넣음.
+// 세그먼트는 모두 전달한 뒤 삭제하며, 재시작 시 남은 세그먼트부터 이어서 전달.
+type Spool struct {
+	dir          string
+	segmentBytes int64
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	segments []segment // 오래된 순, 마지막이 기록 중인 세그먼트일 수 있음
+	nextSeq  int64
+	w        *os.File
+	bw       *bufio.Writer
+	closed   bool // in 이 닫힘 (더 이상 기록 없음)
+	stopped  bool // 전달 중단 요청
+
+	pause       time.Duration // 현재 전달 중지 시간 (Backoff 마다 두 배, Resume 으로 0)
+	pausedUntil time.Time     // 이 시각까지 out 으로 전달하지 않음
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type segment struct {
+	seq   int64
+	docs  int64
+	bytes int64
+}
+
+// Open: dir 의 스풀 오픈. 이전 실행에서 남은 세그먼트가 있으면 먼저 전달 대상으로 등록.
+func Open(dir string, segmentMB int) (*Spool, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("spool dir: %w", err)
+	}
+	s := &Spool{
+		dir:          dir,
+		segmentBytes: int64(segmentMB) << 20,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	if s.segmentBytes <= 0 {
+		s.segmentBytes = defaultSegmentBytes
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read spool dir: %w", err)
+	}
+	for _, e := range entries {
+		seq, ok := parseSegName(e.Name())
+		if !ok || e.IsDir() {
+			continue
+		}
+		docs, size, err := countLines(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s.segments = append(s.segments, segment{seq: seq, docs: docs, bytes: size})
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+	return s, nil
+}
+
+// Run: in → (메모리 또는 디스크) → out 전달 시작. in 이 닫히고 스풀이 비면 out 을 닫음.
+// Stop 이 호출되면 남은 문서는 디스크에 둔 채 out 을 닫음.
+func (s *Spool) Run(logger *logrus.Logger, in <-chan model.ElasticDocument, out chan<- model.ElasticDocument) {
+	go s.ingest(logger, in, out)
+	go s.drain(logger, out)
+}
+
+// Stop: 전달 중단. 기록 중인 세그먼트를 닫고 out 을 닫을 때까지 대기.
+func (s *Spool) Stop() {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stop)
+	}
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	<-s.done
+}
+
+// Requeue: 출력에서 요청 단위로 실패한 문서를 세그먼트 끝에 다시 기록.
+// 전달이 끝난 뒤(종료 중)에 들어온 문서는 다음 실행에서 전달.
+func (s *Spool) Requeue(doc model.ElasticDocument) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.append(doc); err != nil {
+		return err
+	}
+	if s.closed || s.stopped {
+		// ingest 가 끝났으면 세그먼트를 닫아 둠 (drain 이 읽거나 다음 실행에서 읽음)
+		if err := s.closeWriter(); err != nil {
+			return err
+		}
+	}
+	s.cond.Broadcast()
+	return nil
+}
+
+// Backoff: 출력 실패(ES 벌크 요청 실패 등)를 알림. 전달을 잠시 멈추고, 그동안 들어오는 문서는 세그먼트에 기록.
+// 중지 구간 안에서 다시 호출되면 같은 실패로 보고, 구간이 지난 뒤 또 실패하면 중지 시간을 두 배로 늘림 (최대 maxPause).
+func (s *Spool) Backoff() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Before(s.pausedUntil) {
+		return
+	}
+	s.pause *= 2
+	if s.pause < minPause {
+		s.pause = minPause
+	}
+	if s.pause > maxPause {
+		s.pause = maxPause
+	}
+	s.pausedUntil = now.Add(s.pause)
+}
+
+// Resume: 출력이 다시 성공하면 중지 시간 초기화 (진행 중인 중지 구간은 그대로 끝남)
+func (s *Spool) Resume() {
+	s.mu.Lock()
+	s.pause = 0
+	s.mu.Unlock()
+}
+
+// Done: out 이 닫히면 닫히는 채널
+func (s *Spool) Done() <-chan struct{} {
+	return s.done
+}
+
+// Stats: 현재 적체 현황
+func (s *Spool) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := Stats{Segments: len(s.segments)}
+	for _, seg := range s.segments {
+		st.Docs += seg.docs
+		st.Bytes += seg.bytes
+	}
+	return st
+}
+
+// StartReport: interval 마다 적체가 있으면 로그로 남김
+func (s *Spool) StartReport(logger *logrus.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if st := s.Stats(); st.Docs > 0 {
+					logger.Warnf("스풀 적체: 세그먼트=%d 문서=%d 크기=%dMB", st.Segments, st.Docs, st.Bytes>>20)
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// ingest: 스풀이 비어 있고 전달 중지 상태가 아니며 out 에 여유가 있으면 바로 전달, 아니면 세그먼트에 기록
+func (s *Spool) ingest(logger *logrus.Logger, in <-chan model.ElasticDocument, out chan<- model.ElasticDocument) {
+	for doc := range in {
+		s.mu.Lock()
+		if len(s.segments) == 0 && !s.stopped && !time.Now().Before(s.pausedUntil) {
+			select {
+			case out <- doc:
+				s.mu.Unlock()
+				continue
+			default:
+			}
+		}
+		if err := s.append(doc); err != nil {
+			logger.Errorf("스풀 기록 실패, 문서 버림: %v", err)
+		}
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	if err := s.closeWriter(); err != nil {
+		logger.Errorf("스풀 세그먼트 닫기 실패: %v", err)
+	}
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// drain: 오래된 세그먼트부터 읽어 out 으로 전달하고 다 보낸 세그먼트는 삭제
+func (s *Spool) drain(logger *logrus.Logger, out chan<- model.ElasticDocument) {
+	defer close(s.done)
+	defer close(out)
+
+	for {
+		s.mu.Lock()
+		for len(s.segments) == 0 && !s.closed && !s.stopped {
+			s.cond.Wait()
+		}
+		if s.stopped || len(s.segments) == 0 {
+			// 중단 요청 또는 입력 종료 후 스풀이 빈 경우
+			if err := s.closeWriter(); err != nil {
+				logger.Errorf("스풀 세그먼트 닫기 실패: %v", err)
+			}
+			s.mu.Unlock()
+			return
+		}
+		seg := s.segments[0]
+		// 기록 중인 세그먼트면 닫고 새 세그먼트로 넘겨서 읽기
+		if s.w != nil && s.currentSeq() == seg.seq {
+			if err := s.closeWriter(); err != nil {
+				logger.Errorf("스풀 세그먼트 닫기 실패: %v", err)
+			}
+		}
+		s.mu.Unlock()
+
+		if !s.send(logger, seg.seq, out) {
+			return
+		}
+	}
+}
+
+// send: 세그먼트 하나를 out 으로 전달. 중단되면 false (세그먼트는 남김).
+func (s *Spool) send(logger *logrus.Logger, seq int64, out chan<- model.ElasticDocument) bool {
+	path := s.segPath(seq)
+	f, err := os.Open(path)
+	if err != nil {
+		logger.Errorf("스풀 세그먼트 열기 실패, 건너뜀: %v", err)
+		s.remove(seq)
+		return true
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		var rec record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			logger.Errorf("스풀 문서 디코딩 실패, 건너뜀: %v", err)
+			s.consumed(seq, int64(len(sc.Bytes())+1))
+			continue
+		}
+		doc := rec.document()
+		if !s.hold(logger) {
+			return false
+		}
+		select {
+		case out <- doc:
+			s.consumed(seq, int64(len(sc.Bytes())+1))
+		case <-s.stop:
+			// 이미 보낸 문서는 다음 실행에서 다시 보냄 (문서 ID 가 같으므로 덮어씀)
+			return false
+		}
+	}
+	if err := sc.Err(); err != nil {
+		logger.Errorf("스풀 세그먼트 읽기 실패: %s %v", path, err)
+	}
+	s.remove(seq)
+	return true
+}
+
+// hold: 전달 중지 구간이면 끝날 때까지 대기. 중단 요청이 오면 false.
+func (s *Spool) hold(logger *logrus.Logger) bool {
+	for {
+		s.mu.Lock()
+		wait := time.Until(s.pausedUntil)
+		s.mu.Unlock()
+		if wait <= 0 {
+			return true
+		}
+		logger.Warnf("출력 실패로 스풀 전달 %s 중지", wait.Round(time.Second))
+		select {
+		case <-time.After(wait):
+		case <-s.stop:
+			return false
+		}
+	}
+}
+
+// record: 세그먼트 한 줄. 출력 문서에 싣지 않는 SourceFile 을 같은 위치(source_file)에 함께 기록해
+// 되돌린 문서가 다시 나갈 때도 원본 파일 기준으로 응답을 집계할 수 있게 함.
+type record struct {
+	model.ElasticDocument
+	SourceFile string `json:"source_file,omitempty"`
+}
+
+func newRecord(doc model.ElasticDocument) record {
+	rec := record{ElasticDocument: doc}
+	if doc.SourceFile != nil {
+		rec.SourceFile = *doc.SourceFile
+	}
+	return rec
+}
+
+// document: 기록한 문서에 SourceFile 복원
+func (r record) document() model.ElasticDocument {
+	doc := r.ElasticDocument
+	if r.SourceFile != "" {
+		source := r.SourceFile
+		doc.SourceFile = &source
+	}
+	return doc
+}
+
+// append: 기록 중인 세그먼트에 문서 추가, 크기를 넘으면 다음 세그먼트로 (s.mu 보유 상태)
+func (s *Spool) append(doc model.ElasticDocument) error {
+	line, err := json.Marshal(newRecord(doc))
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	line = append(line, '\n')
+
+	if s.w == nil {
+		if err := s.openWriter(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.bw.Write(line); err != nil {
+		return fmt.Errorf("write segment: %w", err)
+	}
+	if err := s.bw.Flush(); err != nil {
+		return fmt.Errorf("flush segment: %w", err)
+	}
+	last := &s.segments[len(s.segments)-1]
+	last.docs++
+	last.bytes += int64(len(line))
+
+	if last.bytes >= s.segmentBytes {
+		return s.closeWriter()
+	}
+	return nil
+}
+
+func (s *Spool) openWriter() error {
+	seq := s.nextSeq
+	f, err := os.OpenFile(s.segPath(seq), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open segment: %w", err)
+	}
+	s.nextSeq++
+	s.w, s.bw = f, bufio.NewWriter(f)
+	s.segments = append(s.segments, segment{seq: seq})
+	return nil
+}
+
+func (s *Spool) closeWriter() error {
+	if s.w == nil {
+		return nil
+	}
+	flushErr := s.bw.Flush()
+	closeErr := s.w.Close()
+	s.w, s.bw = nil, nil
+	if flushErr != nil {
+		return fmt.Errorf("flush segment: %w", flushErr)
+	}
+	return closeErr
+}
+
+// currentSeq: 기록 중인 세그먼트 순번 (s.w != nil 일 때 마지막 세그먼트)
+func (s *Spool) currentSeq() int64 {
+	return s.segments[len(s.segments)-1].seq
+}
+
+// consumed: 전달한 문서만큼 적체 현황 차감
+func (s *Spool) consumed(seq, n int64) {
+	s.mu.Lock()
+	if len(s.segments) > 0 && s.segments[0].seq == seq {
+		s.segments[0].docs--
+		s.segments[0].bytes -= n
+	}
+	s.mu.Unlock()
+}
+
+// remove: 다 보낸 세그먼트 삭제
+func (s *Spool) remove(seq int64) {
+	s.mu.Lock()
+	if len(s.segments) > 0 && s.segments[0].seq == seq {
+		s.segments = s.segments[1:]
+	}
+	s.mu.Unlock()
+	_ = os.Remove(s.segPath(seq))
+}
+
+func (s *Spool) segPath(seq int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s%016d%s", segPrefix, seq, segSuffix))
+}
+
+func parseSegName(name string) (int64, bool) {
+	if !strings.HasPrefix(name, segPrefix) || !strings.HasSuffix(name, segSuffix) {
+		return 0, false
+	}
+	seq, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, segPrefix), segSuffix), 10, 64)
+	return seq, err == nil
+}
+
+// countLines: 재시작 시 남은 세그먼트의 문서 수와 크기
+func countLines(path string) (int64, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("open segment: %w", err)
+	}
+	defer f.Close()
+
+	var docs, size int64
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		docs++
+		size += int64(len(sc.Bytes())) + 1
+	}
+	if err := sc.Err(); err != nil {
+		return 0, 0, fmt.Errorf("read segment %s: %w", path, err)
+	}
+	return docs, size, nil
+}
@@ -0,0 +1,158 @@
+package spool
+
+import (
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"io"
+	"same-parser/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDoc(field string) model.ElasticDocument {
+	return model.ElasticDocument{Data: model.Data{Field: field}}
+}
+
+// recv: out 에서 문서 하나를 받음 (timeout 안에 없으면 false)
+func recv(out <-chan model.ElasticDocument, timeout time.Duration) (model.ElasticDocument, bool) {
+	select {
+	case doc, ok := <-out:
+		return doc, ok
+	case <-time.After(timeout):
+		return model.ElasticDocument{}, false
+	}
+}
+
+// TestSpoolBackoff: Backoff 후에는 out 에 여유가 있어도 전달을 멈추고 디스크에 쌓았다가,
+// 중지 구간이 지나면 되돌린 문서(Requeue)까지 모두 전달해야 함
+func TestSpoolBackoff(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	s, err := Open(t.TempDir(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := make(chan model.ElasticDocument)
+	out := make(chan model.ElasticDocument, 10)
+	s.Run(logger, in, out)
+
+	in <- testDoc("a")
+	if doc, ok := recv(out, time.Second); !ok || doc.Data.Field != "a" {
+		t.Fatalf("direct delivery = %v %v", doc.Data.Field, ok)
+	}
+
+	// 출력 실패: 실패한 문서 되돌림 + 전달 중지
+	retried := testDoc("a")
+	retried.RetrySink = "elasticsearch"
+	if err := s.Requeue(retried); err != nil {
+		t.Fatal(err)
+	}
+	s.Backoff()
+	in <- testDoc("b")
+	if doc, ok := recv(out, 300*time.Millisecond); ok {
+		t.Fatalf("delivered %q while paused", doc.Data.Field)
+	}
+	if st := s.Stats(); st.Docs != 2 {
+		t.Errorf("spooled docs = %d, want 2", st.Docs)
+	}
+
+	// 중지 구간(minPause)이 지나면 순서대로 전달
+	s.Resume()
+	var got []string
+	for i := 0; i < 2; i++ {
+		doc, ok := recv(out, 3*minPause)
+		if !ok {
+			t.Fatalf("not delivered after pause, got %v", got)
+		}
+		got = append(got, doc.Data.Field+"/"+doc.RetrySink)
+	}
+	if got[0] != "a/elasticsearch" || got[1] != "b/" {
+		t.Errorf("delivered = %v", got)
+	}
+
+	close(in)
+	if _, ok := recv(out, time.Second); ok {
+		t.Error("unexpected document after input closed")
+	}
+	<-s.Done()
+	if st := s.Stats(); st.Docs != 0 || st.Segments != 0 {
+		t.Errorf("stats after drain = %+v", st)
+	}
+}
+
+// TestSpoolBackoffGrows: 중지 구간 안의 Backoff 는 한 번으로 보고, 구간이 지난 뒤의 실패는 중지 시간을 두 배로
+func TestSpoolBackoffGrows(t *testing.T) {
+	s, err := Open(t.TempDir(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Backoff()
+	s.Backoff()
+	if s.pause != minPause {
+		t.Fatalf("pause = %s, want %s", s.pause, minPause)
+	}
+	s.pausedUntil = time.Time{} // 구간 종료
+	s.Backoff()
+	if s.pause != 2*minPause {
+		t.Fatalf("pause = %s, want %s", s.pause, 2*minPause)
+	}
+	for i := 0; i < 10; i++ {
+		s.pausedUntil = time.Time{}
+		s.Backoff()
+	}
+	if s.pause != maxPause {
+		t.Errorf("pause = %s, want %s", s.pause, maxPause)
+	}
+	s.Resume()
+	s.pausedUntil = time.Time{}
+	s.Backoff()
+	if s.pause != minPause {
+		t.Errorf("pause after Resume = %s, want %s", s.pause, minPause)
+	}
+}
+
+// TestSpoolKeepsSourceFile: 출력 문서에 싣지 않는 SourceFile 도 스풀을 거친 뒤 그대로 남아 있어야 응답을 원본 파일에 집계할 수 있음
+func TestSpoolKeepsSourceFile(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	s, err := Open(t.TempDir(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := make(chan model.ElasticDocument)
+	out := make(chan model.ElasticDocument, 10)
+	s.Run(logger, in, out)
+
+	source := "/scan/A.xml"
+	doc := testDoc("a")
+	doc.SourceFile = &source
+	doc.RetrySink = "elasticsearch"
+	if err := s.Requeue(doc); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := recv(out, time.Second)
+	if !ok {
+		t.Fatal("requeued document not delivered")
+	}
+	if got.SourceFile == nil || *got.SourceFile != source || got.RetrySink != "elasticsearch" {
+		t.Errorf("delivered source_file = %v retry_sink = %q", got.SourceFile, got.RetrySink)
+	}
+	close(in)
+	<-s.Done()
+
+	// 이전 형식(문서에 source_file 이 있던 때)의 세그먼트 줄도 같은 위치에서 읽음
+	var rec record
+	if err := json.Unmarshal([]byte(`{"data":{"result":1,"field":"a"},"source_file":"/scan/B.xml"}`), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if d := rec.document(); d.SourceFile == nil || *d.SourceFile != "/scan/B.xml" || d.Data.Field != "a" {
+		t.Errorf("decoded = %+v", d)
+	}
+	// 출력 문서 자체에는 없음
+	if b, _ := json.Marshal(doc); strings.Contains(string(b), "source_file") {
+		t.Errorf("document json = %s", b)
+	}
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"same-parser/internal/model"
+	"strconv"
+	"strings"
+)
+
+// columnName: 추가 컬럼 이름 (SQL 식별자로 쓰므로 영문/숫자/밑줄만 허용)
+var columnName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Attributes: ru_mapping 고정 컬럼 외에 읽을 추가 컬럼.
+// Columns 는 매핑의 Attrs 로, Latitude/Longitude 는 Location(geo_point) 으로 적재되어 문서에 그대로 복사됨.
+type Attributes struct {
+	Columns   []string // Attrs 로 적재할 추가 컬럼 (예: site_name, band, region)
+	Latitude  string   // 위도 컬럼 (경도와 함께 지정하면 Location 생성)
+	Longitude string   // 경도 컬럼
+}
+
+// Validate: 컬럼 이름 확인
+func (a Attributes) Validate() error {
+	for _, col := range a.columns() {
+		if !columnName.MatchString(col) {
+			return fmt.Errorf("invalid mapping attribute column: %q", col)
+		}
+	}
+	if (a.Latitude == "") != (a.Longitude == "") {
+		return fmt.Errorf("mapping attributes: latitude and longitude must be set together")
+	}
+	return nil
+}
+
+// columns: 추가로 읽을 컬럼 (Columns, 위도, 경도 순, 중복 제거)
+func (a Attributes) columns() []string {
+	var cols []string
+	seen := make(map[string]bool)
+	for _, col := range append(append([]string(nil), a.Columns...), a.Latitude, a.Longitude) {
+		if col == "" || seen[col] {
+			continue
+		}
+		seen[col] = true
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// apply: get 으로 읽은 추가 컬럼을 m 에 반영. 빈 값은 넣지 않고, 위경도가 숫자가 아니거나 범위를 벗어나면 Location 없음.
+func (a Attributes) apply(m *model.RuMapping, get func(col string) (string, bool)) {
+	for _, col := range a.Columns {
+		v, ok := get(col)
+		if !ok || v == "" {
+			continue
+		}
+		if m.Attrs == nil {
+			m.Attrs = make(map[string]string, len(a.Columns))
+		}
+		m.Attrs[col] = v
+	}
+	if a.Latitude == "" {
+		return
+	}
+	latStr, ok1 := get(a.Latitude)
+	lonStr, ok2 := get(a.Longitude)
+	if !ok1 || !ok2 {
+		return
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return
+	}
+	m.Location = &model.GeoPoint{Lat: lat, Lon: lon}
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"same-parser/internal/model"
+	"strings"
+)
+
+// CSV: 헤더 행이 있는 CSV 파일 매핑 공급원 (스프레드시트 내보내기 등).
+// 헤더는 ru_mapping 컬럼 이름(대소문자 무관), ru_param 필수, 나머지 컬럼은 없거나 비어 있으면 null.
+type CSV struct {
+	path  string
+	attrs Attributes
+}
+
+func NewCSV(path string, attrs Attributes) *CSV {
+	return &CSV{path: path, attrs: attrs}
+}
+
+func (c *CSV) Name() string { return "csv:" + c.path }
+func (c *CSV) Path() string { return c.path }
+func (c *CSV) Close() error { return nil }
+
+func (c *CSV) Load() (map[string][]model.RuMapping, int, error) {
+	f, err := os.Open(c.path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("open csv: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, 0, fmt.Errorf("read csv header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		// 엑셀 CSV 의 UTF-8 BOM 제거
+		h = strings.TrimPrefix(h, "\ufeff")
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := index["ru_param"]; !ok {
+		return nil, 0, fmt.Errorf("csv header has no ru_param column")
+	}
+
+	out := make(map[string][]model.RuMapping)
+	count := 0
+	for line := 2; ; line++ {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("read csv: %w", err)
+		}
+		ruParam, m, err := fromRecord(func(col string) (string, bool) {
+			i, ok := index[strings.ToLower(col)]
+			if !ok || i >= len(rec) {
+				return "", false
+			}
+			return strings.TrimSpace(rec[i]), true
+		}, c.attrs)
+		if err != nil {
+			return nil, 0, fmt.Errorf("csv line %d: %w", line, err)
+		}
+		out[ruParam] = append(out[ruParam], m)
+		count++
+	}
+	return out, count, nil
+}
+
+// JSON: JSON 파일 매핑 공급원. 형식은 decodeJSON 참고.
+type JSON struct {
+	path  string
+	attrs Attributes
+}
+
+func NewJSON(path string, attrs Attributes) *JSON {
+	return &JSON{path: path, attrs: attrs}
+}
+
+func (j *JSON) Name() string { return "json:" + j.path }
+func (j *JSON) Path() string { return j.path }
+func (j *JSON) Close() error { return nil }
+
+func (j *JSON) Load() (map[string][]model.RuMapping, int, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("open json: %w", err)
+	}
+	defer f.Close()
+	return decodeJSON(f, j.attrs)
+}
+
+// decodeJSON: ru_mapping 컬럼 이름을 키로 하는 객체 배열, 또는 그 배열을 "ru_mapping" 키에 담은 객체.
+// 값은 문자열, 숫자, null 허용.
+func decodeJSON(r io.Reader, attrs Attributes) (map[string][]model.RuMapping, int, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber() // 큰 정수(cell_id 등)가 지수 표기로 바뀌지 않도록
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, 0, fmt.Errorf("decode json: %w", err)
+	}
+	if obj, ok := doc.(map[string]interface{}); ok {
+		doc = obj["ru_mapping"]
+	}
+	records, ok := doc.([]interface{})
+	if !ok {
+		return nil, 0, fmt.Errorf("decode json: expected an array of ru_mapping records")
+	}
+
+	out := make(map[string][]model.RuMapping)
+	for i, item := range records {
+		rec, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, 0, fmt.Errorf("json record %d: not an object", i)
+		}
+		ruParam, m, err := fromRecord(func(col string) (string, bool) {
+			v, ok := rec[col]
+			if !ok || v == nil {
+				return "", false
+			}
+			return strings.TrimSpace(fmt.Sprint(v)), true
+		}, attrs)
+		if err != nil {
+			return nil, 0, fmt.Errorf("json record %d: %w", i, err)
+		}
+		out[ruParam] = append(out[ruParam], m)
+	}
+	return out, len(records), nil
+}
@@ -0,0 +1,181 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"same-parser/internal/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+// historyTimeLayout: 이력 DB / ru_mapping_history 시각 형식 (로컬 시간)
+const historyTimeLayout = "2006-01-02 15:04:05"
+
+// Version: ru_param 하나의 특정 기간 매핑. [ValidFrom, ValidTo) 구간에 유효.
+type Version struct {
+	ValidFrom time.Time // zero 면 처음부터
+	ValidTo   time.Time // zero 면 현재까지
+	Mappings  []model.RuMapping
+}
+
+// covers: at 시점에 유효한 버전인지
+func (v Version) covers(at time.Time) bool {
+	if !v.ValidFrom.IsZero() && at.Before(v.ValidFrom) {
+		return false
+	}
+	return v.ValidTo.IsZero() || at.Before(v.ValidTo)
+}
+
+func findVersion(versions []Version, at time.Time) ([]model.RuMapping, bool) {
+	for _, v := range versions {
+		if v.covers(at) {
+			return v.Mappings, true
+		}
+	}
+	return nil, false
+}
+
+const historySchema = `
+	CREATE TABLE IF NOT EXISTS ru_mapping_version (
+		ru_param   TEXT NOT NULL,
+		valid_from TEXT NOT NULL, -- '' 이면 처음부터
+		valid_to   TEXT,          -- NULL 이면 현재 버전
+		mappings   TEXT NOT NULL  -- []model.RuMapping JSON
+	);
+	CREATE INDEX IF NOT EXISTS ru_mapping_version_key ON ru_mapping_version(ru_param, valid_to);
+`
+
+// History: 재적재 때마다 바뀐 ru_param 의 이전/새 버전을 기록하는 SQLite 이력 DB.
+// 재시작 후에도 과거 파일(backfill, 재처리)을 측정 시점의 매핑으로 보강하기 위해 사용.
+type History struct {
+	db *sql.DB
+}
+
+// OpenHistory: 이력 DB 오픈 및 테이블 생성
+func OpenHistory(path string) (*History, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("open mapping history: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create mapping history schema: %w", err)
+	}
+	return &History{db: db}, nil
+}
+
+func (h *History) Close() error {
+	return h.db.Close()
+}
+
+// load: 현재 버전(ru_param → 버전)과 지난 버전(ru_param → ValidFrom 순 목록). cutoff 이전에 끝난 버전은 제외.
+func (h *History) load(cutoff time.Time) (map[string]Version, map[string][]Version, error) {
+	rows, err := h.db.Query(`
+		SELECT ru_param, valid_from, valid_to, mappings
+		FROM ru_mapping_version
+		WHERE valid_to IS NULL OR valid_to >= ?`, formatHistoryTime(cutoff))
+	if err != nil {
+		return nil, nil, fmt.Errorf("query mapping history: %w", err)
+	}
+	defer rows.Close()
+
+	current := make(map[string]Version)
+	past := make(map[string][]Version)
+	for rows.Next() {
+		var ruParam, from, body string
+		var to sql.NullString
+		if err := rows.Scan(&ruParam, &from, &to, &body); err != nil {
+			return nil, nil, fmt.Errorf("scan mapping history: %w", err)
+		}
+		var v Version
+		if v.ValidFrom, err = parseHistoryTime(from); err != nil {
+			return nil, nil, err
+		}
+		if to.Valid {
+			if v.ValidTo, err = parseHistoryTime(to.String); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := json.Unmarshal([]byte(body), &v.Mappings); err != nil {
+			return nil, nil, fmt.Errorf("decode mapping history %s: %w", ruParam, err)
+		}
+		if to.Valid {
+			past[ruParam] = append(past[ruParam], v)
+		} else {
+			current[ruParam] = v
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("query mapping history: %w", err)
+	}
+	for _, vs := range past {
+		sortVersions(vs)
+	}
+	return current, past, nil
+}
+
+// save: closed 의 현재 버전을 닫고(valid_to 기록) opened 를 새 현재 버전으로 추가, cutoff 이전에 끝난 버전 삭제
+func (h *History) save(closed map[string]Version, opened map[string]Version, cutoff time.Time) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin mapping history: %w", err)
+	}
+	defer tx.Rollback()
+
+	for ruParam, v := range closed {
+		if _, err := tx.Exec(`UPDATE ru_mapping_version SET valid_to = ? WHERE ru_param = ? AND valid_to IS NULL`,
+			formatHistoryTime(v.ValidTo), ruParam); err != nil {
+			return fmt.Errorf("close mapping version %s: %w", ruParam, err)
+		}
+	}
+	for ruParam, v := range opened {
+		body, err := json.Marshal(v.Mappings)
+		if err != nil {
+			return fmt.Errorf("encode mapping version %s: %w", ruParam, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO ru_mapping_version (ru_param, valid_from, valid_to, mappings) VALUES (?, ?, NULL, ?)`,
+			ruParam, formatHistoryTime(v.ValidFrom), string(body)); err != nil {
+			return fmt.Errorf("open mapping version %s: %w", ruParam, err)
+		}
+	}
+	if !cutoff.IsZero() {
+		if _, err := tx.Exec(`DELETE FROM ru_mapping_version WHERE valid_to IS NOT NULL AND valid_to < ?`, formatHistoryTime(cutoff)); err != nil {
+			return fmt.Errorf("prune mapping history: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit mapping history: %w", err)
+	}
+	return nil
+}
+
+func sortVersions(vs []Version) {
+	sort.Slice(vs, func(i, j int) bool { return vs[i].ValidFrom.Before(vs[j].ValidFrom) })
+}
+
+func formatHistoryTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(historyTimeLayout)
+}
+
+// parseHistoryTime: "2006-01-02 15:04:05", RFC3339, "2006-01-02" 허용 (시간대 없으면 로컬). 빈 값은 zero.
+func parseHistoryTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{historyTimeLayout, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid mapping history time: %q", s)
+}
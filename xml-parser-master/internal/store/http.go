@@ -0,0 +1,50 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"same-parser/internal/model"
+	"time"
+)
+
+// HTTP: 인벤토리 REST API 등 JSON 을 돌려주는 HTTP(S) 엔드포인트 매핑 공급원. 응답 형식은 decodeJSON 과 같음.
+// 파일 감시 대상이 없으므로 주기 재적재(mapping.reload_interval_min) 또는 SIGHUP/관리 API 로 갱신.
+type HTTP struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+	attrs   Attributes
+}
+
+// NewHTTP: timeout 이 0 이하면 30초
+func NewHTTP(url string, headers map[string]string, timeout time.Duration, attrs Attributes) *HTTP {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &HTTP{url: url, headers: headers, client: &http.Client{Timeout: timeout}, attrs: attrs}
+}
+
+func (h *HTTP) Name() string { return "http:" + h.url }
+func (h *HTTP) Close() error { return nil }
+
+func (h *HTTP) Load() (map[string][]model.RuMapping, int, error) {
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("http request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range h.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("http get: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, 0, fmt.Errorf("http get: %s: %s", resp.Status, body)
+	}
+	return decodeJSON(resp.Body, h.attrs)
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"same-parser/internal/model"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// diffSample: 로그에 남기는 ru_param 예시 개수
+const diffSample = 5
+
+// Diff: 재적재 전후 ru_param 비교 결과 (각 목록은 정렬됨)
+type Diff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Empty: 바뀐 ru_param 이 없는지
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d Diff) String() string {
+	return fmt.Sprintf("추가 %d%s, 삭제 %d%s, 변경 %d%s",
+		len(d.Added), sample(d.Added), len(d.Removed), sample(d.Removed), len(d.Changed), sample(d.Changed))
+}
+
+func sample(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	if len(keys) <= diffSample {
+		return " [" + strings.Join(keys, ", ") + "]"
+	}
+	return " [" + strings.Join(keys[:diffSample], ", ") + ", ...]"
+}
+
+// diffMappings: ru_param 단위 추가/삭제/변경 비교
+func diffMappings(prev, next map[string][]model.RuMapping) Diff {
+	var d Diff
+	for k, v := range next {
+		old, ok := prev[k]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, k)
+		case !reflect.DeepEqual(old, v):
+			d.Changed = append(d.Changed, k)
+		}
+	}
+	for k := range prev {
+		if _, ok := next[k]; !ok {
+			d.Removed = append(d.Removed, k)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	return d
+}
+
+// ReloadConfig: ru_mapping 자동 재적재 설정
+type ReloadConfig struct {
+	Paths    []string      // 감시할 공급원 파일 경로 (비우면 파일 감시 안 함)
+	Interval time.Duration // 주기 재적재 간격 (0 이면 안 함)
+	Debounce time.Duration // 파일 변경이 멈춘 뒤 재적재까지 대기 시간 (기본 2초)
+	SIGHUP   bool          // SIGHUP 수신 시 재적재
+}
+
+// StartAutoReload: 공급원 파일(SQLite 는 -wal, -journal 포함) 변경, SIGHUP, 주기 중 하나가 발생하면 재적재.
+// 파일 변경은 debounce 로 모아서 한 번만 재적재하며, 재적재는 한 고루틴에서 순서대로 실행.
+// 파일 교체(rename)도 감지하도록 파일이 아닌 상위 디렉터리를 감시.
+func (s *Store) StartAutoReload(src Source, logger *logrus.Logger, cfg ReloadConfig) error {
+	if cfg.Debounce <= 0 {
+		cfg.Debounce = 2 * time.Second
+	}
+
+	var events <-chan fsnotify.Event
+	var watchErrs <-chan error
+	var names map[string]bool
+	if len(cfg.Paths) > 0 {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("ru_mapping watcher: %w", err)
+		}
+		names = make(map[string]bool)
+		watched := make(map[string]bool)
+		for _, path := range cfg.Paths {
+			path = filepath.Clean(path)
+			dir := filepath.Dir(path)
+			if !watched[dir] {
+				if err := watcher.Add(dir); err != nil {
+					watcher.Close()
+					return fmt.Errorf("ru_mapping watch %s: %w", dir, err)
+				}
+				watched[dir] = true
+			}
+			// -shm 은 읽기만 해도 바뀌므로 제외
+			names[path], names[path+"-wal"], names[path+"-journal"] = true, true, true
+		}
+		events, watchErrs = watcher.Events, watcher.Errors
+	}
+
+	var hup chan os.Signal
+	if cfg.SIGHUP {
+		hup = make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+	}
+
+	var tick <-chan time.Time
+	if cfg.Interval > 0 {
+		tick = time.NewTicker(cfg.Interval).C
+	}
+
+	reload := func(reason string) {
+		diff, err := s.Reload(src)
+		if err != nil {
+			logger.Errorf("Error updating ruMappingMap (%s): %v", reason, err)
+			return
+		}
+		if l, ok := src.(*Layered); ok {
+			for _, layer := range l.Layers() {
+				if layer.Error != "" {
+					logger.Warnf("ru_mapping 공급원 적재 실패, 마지막 결과 사용 (%s): %s", layer.Name, layer.Error)
+				}
+			}
+		}
+		st := s.Stats()
+		if diff.Empty() {
+			logger.Infof("ruMappingMap updated successfully (%s): 변경 없음, 행 %d, 키 %d", reason, st.Rows, st.Keys)
+			return
+		}
+		logger.Infof("ruMappingMap updated successfully (%s): %s, 행 %d, 키 %d", reason, diff, st.Rows, st.Keys)
+	}
+
+	go func() {
+		debounce := time.NewTimer(cfg.Debounce)
+		debounce.Stop()
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					events = nil
+					continue
+				}
+				if names[filepath.Clean(ev.Name)] && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+					debounce.Reset(cfg.Debounce)
+				}
+			case err, ok := <-watchErrs:
+				if !ok {
+					watchErrs = nil
+					continue
+				}
+				logger.Errorf("ru_mapping watcher error: %v", err)
+			case <-debounce.C:
+				reload("file changed")
+			case <-hup:
+				reload("SIGHUP")
+			case <-tick:
+				reload("interval")
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,180 @@
+package store
+
+import (
+	"fmt"
+	"same-parser/internal/model"
+	"strings"
+	"sync"
+)
+
+// Source: ru_mapping 공급원 (SQLite, CSV, JSON 파일, HTTP)
+type Source interface {
+	Name() string                                     // 로그/현황 표시용 (예: "csv:/etc/override.csv")
+	Load() (map[string][]model.RuMapping, int, error) // 전체 조회 (ru_param → 매핑 목록, 행 수)
+	Close() error
+}
+
+// HistorySource: 지난 버전 이력도 제공하는 공급원 (SQLite ru_mapping_history 테이블)
+type HistorySource interface {
+	LoadHistory() (map[string][]Version, error)
+}
+
+// PathSource: 변경 감시 대상 파일이 있는 공급원 (SQLite, CSV, JSON)
+type PathSource interface {
+	Path() string
+}
+
+// LayerStats: 마지막 적재 때 공급원별 결과
+type LayerStats struct {
+	Name       string `json:"name"`
+	Rows       int    `json:"rows"`
+	Keys       int    `json:"keys"`
+	Overridden int    `json:"overridden"`      // 앞 공급원의 같은 ru_param 을 덮어쓴 수
+	Error      string `json:"error,omitempty"` // 선택 공급원 적재 실패 (마지막으로 읽은 결과 사용)
+}
+
+// Layer: 겹칠 공급원 하나
+type Layer struct {
+	Source   Source
+	Optional bool // 실패해도 적재 계속 (마지막으로 읽은 결과, 없으면 빈 결과 사용)
+
+	cache map[string][]model.RuMapping
+	rows  int
+}
+
+// Layered: 여러 공급원을 순서대로 읽어 합침. 같은 ru_param 은 뒤 공급원의 매핑 목록으로 통째로 교체.
+// (예: SQLite 위에 로컬 CSV 로 일부 ru_param 을 수정)
+// 선택(Optional) 이 아닌 공급원 하나라도 실패하면 적재 실패 (이전 매핑 유지).
+type Layered struct {
+	mu     sync.Mutex
+	layers []*Layer
+	last   []LayerStats
+}
+
+func NewLayered(layers ...*Layer) *Layered {
+	return &Layered{layers: layers}
+}
+
+func (l *Layered) Name() string {
+	names := make([]string, len(l.layers))
+	for i, layer := range l.layers {
+		names[i] = layer.Source.Name()
+	}
+	return strings.Join(names, " < ")
+}
+
+// Load: 관리 API 와 자동 갱신이 동시에 부를 수 있으므로 한 번에 하나씩 실행
+func (l *Layered) Load() (map[string][]model.RuMapping, int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	merged := make(map[string][]model.RuMapping)
+	stats := make([]LayerStats, 0, len(l.layers))
+	total := 0
+	for _, layer := range l.layers {
+		src := layer.Source
+		st := LayerStats{Name: src.Name()}
+		m, rows, err := src.Load()
+		switch {
+		case err == nil:
+			layer.cache, layer.rows = m, rows
+		case layer.Optional:
+			st.Error = err.Error()
+			m, rows = layer.cache, layer.rows
+		default:
+			return nil, 0, fmt.Errorf("%s: %w", src.Name(), err)
+		}
+		st.Rows, st.Keys = rows, len(m)
+		for k, v := range m {
+			if _, ok := merged[k]; ok {
+				st.Overridden++
+			}
+			merged[k] = v
+		}
+		stats = append(stats, st)
+		total += rows
+	}
+	l.last = stats
+	return merged, total, nil
+}
+
+// LoadHistory: 이력을 제공하는 공급원의 지난 버전을 모두 합침
+func (l *Layered) LoadHistory() (map[string][]Version, error) {
+	var merged map[string][]Version
+	for _, layer := range l.layers {
+		src := layer.Source
+		hs, ok := src.(HistorySource)
+		if !ok {
+			continue
+		}
+		h, err := hs.LoadHistory()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src.Name(), err)
+		}
+		if merged == nil {
+			merged = h
+			continue
+		}
+		for k, vs := range h {
+			merged[k] = append(merged[k], vs...)
+			sortVersions(merged[k])
+		}
+	}
+	return merged, nil
+}
+
+// Paths: 변경 감시 대상 파일 목록
+func (l *Layered) Paths() []string {
+	var paths []string
+	for _, layer := range l.layers {
+		if ps, ok := layer.Source.(PathSource); ok {
+			paths = append(paths, ps.Path())
+		}
+	}
+	return paths
+}
+
+// Layers: 마지막 적재 때 공급원별 결과
+func (l *Layered) Layers() []LayerStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LayerStats(nil), l.last...)
+}
+
+func (l *Layered) Close() error {
+	var first error
+	for _, layer := range l.layers {
+		if err := layer.Source.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// fromRecord: CSV/JSON 레코드 → (ru_param, 매핑). 컬럼 이름은 ru_mapping 테이블과 같음.
+// get 은 컬럼 이름으로 값을 꺼내며, 없거나 빈 값은 nil. attrs 의 추가 컬럼도 같은 방식으로 읽음.
+func fromRecord(get func(col string) (string, bool), attrs Attributes) (string, model.RuMapping, error) {
+	ruParam, _ := get("ru_param")
+	ruParam = strings.TrimSpace(ruParam)
+	if ruParam == "" {
+		return "", model.RuMapping{}, fmt.Errorf("ru_param is empty")
+	}
+	field := func(col string) *string {
+		v, ok := get(col)
+		if !ok || v == "" {
+			return nil
+		}
+		return &v
+	}
+	m := model.RuMapping{
+		EMS_Id:   field("ems_id"),
+		EMSName:  field("ems_name"),
+		DUId:     field("du_id"),
+		RUId:     field("ru_id"),
+		DU_NAME:  field("du_name"),
+		RU_NAME:  field("ru_name"),
+		CELL_ID:  field("cell_id"),
+		CELL_NUM: field("cell_num"),
+	}
+	attrs.apply(&m, get)
+	return ruParam, m, nil
+}
@@ -0,0 +1,242 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"same-parser/internal/model"
+	"strings"
+)
+
+const getRuMappingQuery = `
+	SELECT 
+		ru_param,
+		ems_id, 
+		ems_name, 
+		du_id,
+		ru_id,
+		du_name,
+		ru_name,
+		cell_id,
+		cell_num%s
+	FROM 
+		ru_mapping
+`
+
+// SQLite: ru_mapping 테이블(tibero-to-sqlite 동기화 결과)을 읽는 매핑 공급원.
+// ru_mapping_history 테이블이 있으면 지난 버전도 제공.
+type SQLite struct {
+	path  string
+	db    *sql.DB
+	attrs Attributes
+}
+
+// NewSQLite: SQLite 매핑 DB 오픈. 파일이 없으면 오류 (빈 DB 파일을 만들지 않도록).
+// attrs 의 추가 컬럼은 ru_mapping 에 있어야 하며, ru_mapping_history 에 없는 컬럼은 null 로 읽음.
+func NewSQLite(path string, attrs Attributes) (*SQLite, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("sqlite mapping source: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("sqlite mapping source: %w", err)
+	}
+	// 동기화 작업이 파일을 통째로 교체(rename)해도 새 파일을 읽도록 유휴 연결을 남기지 않음
+	db.SetMaxIdleConns(0)
+	return &SQLite{path: path, db: db, attrs: attrs}, nil
+}
+
+func (s *SQLite) Name() string {
+	return "sqlite:" + s.path
+}
+
+// Path: 변경 감시 대상 파일
+func (s *SQLite) Path() string {
+	return s.path
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// Load: ru_mapping 전체 조회 (ru_param → 매핑 목록, 행 수)
+func (s *SQLite) Load() (map[string][]model.RuMapping, int, error) {
+	extra := s.attrs.columns()
+	rows, err := s.db.Query(fmt.Sprintf(getRuMappingQuery, selectColumns(extra, nil)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query ru_mapping: %w", err)
+	}
+	defer rows.Close()
+
+	temp := make(map[string][]model.RuMapping)
+	count := 0
+	values := make([]sql.NullString, len(extra))
+	for rows.Next() {
+		var ruParam string
+		var d model.RuMappingDAO
+		dest := []interface{}{&ruParam, &d.EMS_Id, &d.EMSName, &d.DUId, &d.RUId, &d.DU_NAME, &d.RU_NAME, &d.CELL_ID, &d.CELL_NUM}
+		if err = rows.Scan(appendDest(dest, values)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		temp[ruParam] = append(temp[ruParam], s.toRuMapping(d, extra, values))
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return temp, count, nil
+}
+
+const getRuMappingHistoryQuery = `
+	SELECT
+		ru_param,
+		ems_id,
+		ems_name,
+		du_id,
+		ru_id,
+		du_name,
+		ru_name,
+		cell_id,
+		cell_num,
+		valid_from,
+		valid_to%s
+	FROM
+		ru_mapping_history
+	WHERE
+		valid_to IS NOT NULL
+`
+
+// LoadHistory: ru_mapping_history 테이블(ru_mapping 컬럼 + valid_from, valid_to)이 있으면
+// 끝난 버전(valid_to 있음)을 ru_param 별로 읽음. 테이블이 없으면 nil.
+func (s *SQLite) LoadHistory() (map[string][]Version, error) {
+	var name string
+	err := s.db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'ru_mapping_history'`).Scan(&name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ru_mapping_history: %w", err)
+	}
+
+	existing, err := s.tableColumns("ru_mapping_history")
+	if err != nil {
+		return nil, err
+	}
+	extra := s.attrs.columns()
+	rows, err := s.db.Query(fmt.Sprintf(getRuMappingHistoryQuery, selectColumns(extra, existing)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ru_mapping_history: %w", err)
+	}
+	defer rows.Close()
+
+	type span struct {
+		ruParam  string
+		from, to string
+	}
+	grouped := make(map[span]*Version)
+	values := make([]sql.NullString, len(extra))
+	for rows.Next() {
+		var sp span
+		var from sql.NullString
+		var d model.RuMappingDAO
+		dest := []interface{}{&sp.ruParam, &d.EMS_Id, &d.EMSName, &d.DUId, &d.RUId, &d.DU_NAME, &d.RU_NAME, &d.CELL_ID, &d.CELL_NUM, &from, &sp.to}
+		if err := rows.Scan(appendDest(dest, values)...); err != nil {
+			return nil, fmt.Errorf("failed to scan ru_mapping_history row: %w", err)
+		}
+		sp.from = from.String
+		v, ok := grouped[sp]
+		if !ok {
+			v = &Version{}
+			if v.ValidFrom, err = parseHistoryTime(sp.from); err != nil {
+				return nil, err
+			}
+			if v.ValidTo, err = parseHistoryTime(sp.to); err != nil {
+				return nil, err
+			}
+			grouped[sp] = v
+		}
+		v.Mappings = append(v.Mappings, s.toRuMapping(d, extra, values))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ru_mapping_history rows: %w", err)
+	}
+
+	out := make(map[string][]Version)
+	for sp, v := range grouped {
+		out[sp.ruParam] = append(out[sp.ruParam], *v)
+	}
+	for _, vs := range out {
+		sortVersions(vs)
+	}
+	return out, nil
+}
+
+// tableColumns: 테이블의 컬럼 이름 집합
+func (s *SQLite) tableColumns(table string) (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	defer rows.Close()
+	cols := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+		}
+		cols[strings.ToLower(name)] = true
+	}
+	return cols, rows.Err()
+}
+
+// selectColumns: 추가 컬럼 SELECT 목록 (", "col"..."). existing 이 nil 이 아니면 없는 컬럼은 NULL.
+func selectColumns(extra []string, existing map[string]bool) string {
+	var b strings.Builder
+	for _, col := range extra {
+		if existing != nil && !existing[strings.ToLower(col)] {
+			b.WriteString(",\n\t\tNULL")
+			continue
+		}
+		b.WriteString(",\n\t\t\"" + col + "\"")
+	}
+	return b.String()
+}
+
+func appendDest(dest []interface{}, values []sql.NullString) []interface{} {
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	return dest
+}
+
+// toRuMapping: 고정 컬럼과 추가 컬럼(extra 순서의 values) → 매핑
+func (s *SQLite) toRuMapping(d model.RuMappingDAO, extra []string, values []sql.NullString) model.RuMapping {
+	m := model.RuMapping{
+		EMS_Id:   nilIfInvalid(d.EMS_Id),
+		EMSName:  nilIfInvalid(d.EMSName),
+		DUId:     nilIfInvalid(d.DUId),
+		RUId:     nilIfInvalid(d.RUId),
+		DU_NAME:  nilIfInvalid(d.DU_NAME),
+		RU_NAME:  nilIfInvalid(d.RU_NAME),
+		CELL_ID:  nilIfInvalid(d.CELL_ID),
+		CELL_NUM: nilIfInvalid(d.CELL_NUM),
+	}
+	if len(extra) > 0 {
+		s.attrs.apply(&m, func(col string) (string, bool) {
+			for i, c := range extra {
+				if c == col {
+					return values[i].String, values[i].Valid
+				}
+			}
+			return "", false
+		})
+	}
+	return m
+}
+
+func nilIfInvalid(n sql.NullString) *string {
+	if n.Valid {
+		return &n.String
+	}
+	return nil
+}
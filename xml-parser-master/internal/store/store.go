@@ -1,77 +1,69 @@
 package store
 
 import (
-	"database/sql"
 	"fmt"
-	"github.com/sirupsen/logrus"
 	"same-parser/internal/model"
 	"sync"
 	"time"
 )
 
-const getRuMappingQuery = `
-	SELECT 
-		ru_param,
-		ems_id, 
-		ems_name, 
-		du_id,
-		ru_id,
-		du_name,
-		ru_name,
-		cell_id,
-		cell_num
-	FROM 
-		ru_mapping
-`
-
 type Store struct {
+	// reloadMu: 적재(Reload, UseHistory)를 한 번에 하나씩 실행. 맵을 바꾸는 쪽은 reloadMu 와 mutex 를 모두 잡으므로
+	// reloadMu 를 잡은 동안에는 mutex 없이 맵을 읽을 수 있고, 조회(GetAt 등)는 교체하는 짧은 순간에만 mutex 를 기다림.
+	reloadMu  sync.Mutex
 	mutex     sync.Mutex
 	ruMapping map[string][]model.RuMapping
+	stats     LoadStats
+
+	// 시점별 조회(GetAt)용 버전 정보
+	since     map[string]time.Time // ru_param → 현재 매핑의 유효 시작 시각 (zero 면 처음부터)
+	past      map[string][]Version // 재적재 때 바뀌거나 삭제되어 끝난 버전 (ValidFrom 순)
+	external  map[string][]Version // 매핑 DB ru_mapping_history 테이블의 끝난 버전
+	history   *History             // nil 이면 지난 버전을 메모리에만 보관
+	retention time.Duration        // 끝난 버전 보존 기간 (0 이면 제한 없음)
+}
+
+// LoadStats: 마지막 ru_mapping 적재 결과
+type LoadStats struct {
+	LoadedAt time.Time     // 마지막 적재 완료 시각
+	Duration time.Duration // 적재 소요 시간
+	Rows     int           // 적재한 행 수
+	Keys     int           // ru_param 키 수
 }
 
 func NewStore() *Store {
 	return &Store{
 		ruMapping: make(map[string][]model.RuMapping),
+		since:     make(map[string]time.Time),
+		past:      make(map[string][]Version),
 	}
 }
 
-// Init: 애플리케이션 시작 시 DB에서 초기 로드. 메모리에 캐싱.
-func (s *Store) Init(db *sql.DB) error {
-	rows, err := db.Query(getRuMappingQuery)
+// UseHistory: 지난 버전을 이력 DB 에 기록하고, 기록된 버전을 불러옴. Init 전에 호출.
+// 불러온 현재 버전은 첫 적재 때 비교 대상이 되어, 중지된 동안 바뀐 ru_param 도 새 버전으로 기록됨(유효 시작은 적재 시각).
+func (s *Store) UseHistory(h *History, retention time.Duration) error {
+	current, past, err := h.load(cutoff(time.Now(), retention))
 	if err != nil {
-		return fmt.Errorf("failed to query ru_mapping: %w", err)
-	}
-	defer rows.Close()
-
-	temp := make(map[string][]model.RuMapping)
-	for rows.Next() {
-		var ruParam string
-		var d model.RuMappingDAO
-		if err = rows.Scan(&ruParam, &d.EMS_Id, &d.EMSName, &d.DUId, &d.RUId, &d.DU_NAME, &d.RU_NAME, &d.CELL_ID, &d.CELL_NUM); err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
-		}
-		temp[ruParam] = append(temp[ruParam], model.RuMapping{
-			EMS_Id:   nilIfInvalid(d.EMS_Id),
-			EMSName:  nilIfInvalid(d.EMSName),
-			DUId:     nilIfInvalid(d.DUId),
-			RUId:     nilIfInvalid(d.RUId),
-			DU_NAME:  nilIfInvalid(d.DU_NAME),
-			RU_NAME:  nilIfInvalid(d.RU_NAME),
-			CELL_ID:  nilIfInvalid(d.CELL_ID),
-			CELL_NUM: nilIfInvalid(d.CELL_NUM),
-		})
+		return err
 	}
-	if err = rows.Err(); err != nil {
-		return fmt.Errorf("error iterating rows: %w", err)
-	}
-
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
 	s.mutex.Lock()
-	s.ruMapping = temp
-	s.mutex.Unlock()
-
+	defer s.mutex.Unlock()
+	s.history, s.retention, s.past = h, retention, past
+	for k, v := range current {
+		s.ruMapping[k] = v.Mappings
+		s.since[k] = v.ValidFrom
+	}
 	return nil
 }
 
+// Init: 애플리케이션 시작 시 공급원에서 초기 로드. 메모리에 캐싱.
+func (s *Store) Init(src Source) error {
+	_, err := s.Reload(src)
+	return err
+}
+
 func (s *Store) Get(key string) ([]model.RuMapping, bool) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -79,60 +71,148 @@ func (s *Store) Get(key string) ([]model.RuMapping, bool) {
 	return val, ok
 }
 
-// Update: DB에서 재로드하여 맵을 새로 교체(Init과 동일한 동작)
-func (s *Store) Update(db *sql.DB) error {
-	rows, err := db.Query(getRuMappingQuery)
-	if err != nil {
-		return fmt.Errorf("failed to query ru_mapping: %w", err)
+// GetAt: at 시점에 유효했던 매핑 조회. 순서: ru_mapping_history 테이블 → 현재 매핑(유효 시작 이후) → 재적재 때 기록한 지난 버전.
+// 어느 버전에도 해당하지 않으면(이력 수집 전 시점 등) 현재 매핑 반환. at 이 zero 면 Get 과 같음.
+func (s *Store) GetAt(key string, at time.Time) ([]model.RuMapping, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	cur, ok := s.ruMapping[key]
+	if at.IsZero() {
+		return cur, ok
+	}
+	if val, found := findVersion(s.external[key], at); found {
+		return val, true
+	}
+	if ok && !at.Before(s.since[key]) {
+		return cur, true
 	}
-	defer rows.Close()
+	if val, found := findVersion(s.past[key], at); found {
+		return val, true
+	}
+	return cur, ok
+}
 
-	temp := make(map[string][]model.RuMapping)
-	for rows.Next() {
-		var ruParam string
-		var d model.RuMappingDAO
-		if err = rows.Scan(&ruParam, &d.EMS_Id, &d.EMSName, &d.DUId, &d.RUId, &d.DU_NAME, &d.RU_NAME, &d.CELL_ID, &d.CELL_NUM); err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
+// Versions: ru_param 의 모든 버전 (ru_mapping_history 테이블, 지난 버전, 현재 버전 순)
+func (s *Store) Versions(key string) []Version {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var out []Version
+	out = append(out, s.external[key]...)
+	out = append(out, s.past[key]...)
+	if cur, ok := s.ruMapping[key]; ok {
+		out = append(out, Version{ValidFrom: s.since[key], Mappings: cur})
+	}
+	return out
+}
+
+// Stats: 마지막 적재 결과
+func (s *Store) Stats() LoadStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stats
+}
+
+// Update: 공급원에서 재로드하여 맵을 새로 교체(Init과 동일한 동작)
+func (s *Store) Update(src Source) error {
+	_, err := s.Reload(src)
+	return err
+}
+
+// Reload: 공급원에서 전체를 읽은 뒤 맵을 한 번에 교체하고, 이전 맵과의 차이를 반환.
+// 이미 적재된 매핑이 있는데 읽은 행이 0 건이면(동기화 작업 중 테이블 비움 등) 교체하지 않고 오류 반환.
+// 차이 계산과 이력 DB 기록은 조회 잠금 밖에서 하므로 그동안 파서 워커의 GetAt 은 이전 맵으로 계속 조회.
+func (s *Store) Reload(src Source) (Diff, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	start := time.Now()
+	temp, count, err := src.Load()
+	if err != nil {
+		return Diff{}, err
+	}
+	var external map[string][]Version
+	if hs, ok := src.(HistorySource); ok {
+		if external, err = hs.LoadHistory(); err != nil {
+			return Diff{}, err
 		}
-		temp[ruParam] = append(temp[ruParam], model.RuMapping{
-			EMS_Id:   nilIfInvalid(d.EMS_Id),
-			EMSName:  nilIfInvalid(d.EMSName),
-			DUId:     nilIfInvalid(d.DUId),
-			RUId:     nilIfInvalid(d.RUId),
-			DU_NAME:  nilIfInvalid(d.DU_NAME),
-			RU_NAME:  nilIfInvalid(d.RU_NAME),
-			CELL_ID:  nilIfInvalid(d.CELL_ID),
-			CELL_NUM: nilIfInvalid(d.CELL_NUM),
-		})
 	}
-	if err = rows.Err(); err != nil {
-		return fmt.Errorf("error iterating rows: %w", err)
+
+	// 맵은 reloadMu 를 잡은 쪽만 바꾸므로 여기서는 mutex 없이 읽음
+	if count == 0 && len(s.ruMapping) > 0 {
+		return Diff{}, fmt.Errorf("ru_mapping is empty, keeping %d loaded keys", len(s.ruMapping))
+	}
+	now := time.Now().Truncate(time.Second)
+	diff := diffMappings(s.ruMapping, temp)
+	closed, opened := s.versions(diff, temp, now)
+	limit := cutoff(now, s.retention)
+	// 이력 DB 기록에 실패하면 아무것도 바꾸지 않음
+	if s.history != nil {
+		if err := s.history.save(closed, opened, limit); err != nil {
+			return Diff{}, err
+		}
 	}
 
 	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.apply(closed, opened, limit)
 	s.ruMapping = temp
-	s.mutex.Unlock()
+	s.external = external
+	s.stats = LoadStats{LoadedAt: time.Now(), Duration: time.Since(start), Rows: count, Keys: len(temp)}
+	return diff, nil
+}
 
-	return nil
+// versions: 바뀌거나 삭제된 ru_param 의 현재 버전을 now 에 끝내고(closed), 추가되거나 바뀐 ru_param 의 새 버전을 now 부터 시작(opened).
+// 비교할 매핑이 없는 첫 적재는 처음부터 유효한 버전으로 기록. reloadMu 를 잡고 호출.
+func (s *Store) versions(diff Diff, next map[string][]model.RuMapping, now time.Time) (closed, opened map[string]Version) {
+	from := now
+	if len(s.ruMapping) == 0 {
+		from = time.Time{}
+	}
+	closed = make(map[string]Version, len(diff.Changed)+len(diff.Removed))
+	opened = make(map[string]Version, len(diff.Added)+len(diff.Changed))
+	for _, k := range diff.Removed {
+		closed[k] = Version{ValidFrom: s.since[k], ValidTo: now, Mappings: s.ruMapping[k]}
+	}
+	for _, k := range diff.Changed {
+		closed[k] = Version{ValidFrom: s.since[k], ValidTo: now, Mappings: s.ruMapping[k]}
+		opened[k] = Version{ValidFrom: from, Mappings: next[k]}
+	}
+	for _, k := range diff.Added {
+		opened[k] = Version{ValidFrom: from, Mappings: next[k]}
+	}
+	return closed, opened
 }
 
-// StartPeriodicUpdate: 6시간 간격으로 백그라운드 업데이트 시작, 오류는 로거에 기록
-func (s *Store) StartPeriodicUpdate(db *sql.DB, logger *logrus.Logger) {
-	ticker := time.NewTicker(6 * time.Hour)
-	go func() {
-		for range ticker.C {
-			if err := s.Update(db); err != nil {
-				logger.Errorf("Error updating ruMappingMap: %v", err)
+// apply: versions 결과를 메모리 버전 정보에 반영하고 limit 보다 먼저 끝난 지난 버전 정리. reloadMu 와 mutex 를 잡고 호출.
+func (s *Store) apply(closed, opened map[string]Version, limit time.Time) {
+	for k, v := range closed {
+		s.past[k] = append(s.past[k], v)
+		delete(s.since, k)
+	}
+	for k, v := range opened {
+		s.since[k] = v.ValidFrom
+	}
+	if !limit.IsZero() {
+		for k, vs := range s.past {
+			kept := vs[:0]
+			for _, v := range vs {
+				if !v.ValidTo.Before(limit) {
+					kept = append(kept, v)
+				}
+			}
+			if len(kept) == 0 {
+				delete(s.past, k)
 			} else {
-				logger.Infof("ruMappingMap updated successfully.")
+				s.past[k] = kept
 			}
 		}
-	}()
+	}
 }
 
-func nilIfInvalid(n sql.NullString) *string {
-	if n.Valid {
-		return &n.String
+// cutoff: 보존 기간 기준 시각 (retention 이 0 이면 zero)
+func cutoff(now time.Time, retention time.Duration) time.Time {
+	if retention <= 0 {
+		return time.Time{}
 	}
-	return nil
+	return now.Add(-retention)
 }
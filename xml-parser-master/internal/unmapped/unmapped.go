@@ -0,0 +1,235 @@
+package unmapped
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const schema = `
+	CREATE TABLE IF NOT EXISTS unmapped_ru_param (
+		ru_param   TEXT NOT NULL,
+		du         TEXT NOT NULL,
+		montype    TEXT NOT NULL,
+		count      INTEGER NOT NULL,
+		first_seen TEXT NOT NULL,
+		last_seen  TEXT NOT NULL,
+		PRIMARY KEY (ru_param, du, montype)
+	);
+	CREATE INDEX IF NOT EXISTS unmapped_ru_param_last_seen ON unmapped_ru_param(last_seen);
+`
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// Key: 집계 단위 (ru_param, DU(managedElement), montype)
+type Key struct {
+	RuParam string
+	DU      string
+	Montype string
+}
+
+// Entry: 매핑 누락 집계 한 건
+type Entry struct {
+	Key
+	Count     int64
+	FirstSeen string
+	LastSeen  string
+}
+
+type pending struct {
+	count     int64
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// Tracker: ru_mapping 에 없는 ru_param 집계. 메모리에 모았다가 Flush 때 SQLite 테이블(unmapped_ru_param)에 누적.
+type Tracker struct {
+	db *sql.DB
+
+	mu      sync.Mutex
+	pending map[Key]*pending
+}
+
+// Open: 집계 DB 오픈 및 테이블 생성
+func Open(path string) (*Tracker, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("open unmapped db: %w", err)
+	}
+	// 쓰기 잠금 경합 방지
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create unmapped schema: %w", err)
+	}
+	return &Tracker{db: db, pending: make(map[Key]*pending)}, nil
+}
+
+// Record: 매핑 없이 전송한 문서 n 건 기록
+func (t *Tracker) Record(k Key, n int, at time.Time) {
+	if n <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.pending[k]
+	if !ok {
+		t.pending[k] = &pending{count: int64(n), firstSeen: at, lastSeen: at}
+		return
+	}
+	p.count += int64(n)
+	if at.Before(p.firstSeen) {
+		p.firstSeen = at
+	}
+	if at.After(p.lastSeen) {
+		p.lastSeen = at
+	}
+}
+
+// Flush: 모아 둔 집계를 테이블에 누적 (실패하면 다음 Flush 때 다시 시도)
+func (t *Tracker) Flush() error {
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[Key]*pending)
+	t.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := t.write(batch); err != nil {
+		// 되돌려 놓고 다음에 다시 시도
+		t.mu.Lock()
+		for k, p := range batch {
+			t.merge(k, p)
+		}
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (t *Tracker) write(batch map[Key]*pending) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin unmapped flush: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO unmapped_ru_param (ru_param, du, montype, count, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ru_param, du, montype) DO UPDATE SET
+			count = count + excluded.count,
+			first_seen = min(first_seen, excluded.first_seen),
+			last_seen = max(last_seen, excluded.last_seen)`)
+	if err != nil {
+		return fmt.Errorf("prepare unmapped flush: %w", err)
+	}
+	defer stmt.Close()
+
+	for k, p := range batch {
+		if _, err := stmt.Exec(k.RuParam, k.DU, k.Montype, p.count, p.firstSeen.Format(timeLayout), p.lastSeen.Format(timeLayout)); err != nil {
+			return fmt.Errorf("flush unmapped %s: %w", k.RuParam, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit unmapped flush: %w", err)
+	}
+	return nil
+}
+
+// merge: 실패한 배치를 대기 집계에 합침 (t.mu 보유 상태)
+func (t *Tracker) merge(k Key, p *pending) {
+	cur, ok := t.pending[k]
+	if !ok {
+		t.pending[k] = p
+		return
+	}
+	cur.count += p.count
+	if p.firstSeen.Before(cur.firstSeen) {
+		cur.firstSeen = p.firstSeen
+	}
+	if p.lastSeen.After(cur.lastSeen) {
+		cur.lastSeen = p.lastSeen
+	}
+}
+
+// StartFlush: interval 마다 Flush, 오류는 로거에 기록
+func (t *Tracker) StartFlush(logger *logrus.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := t.Flush(); err != nil {
+				logger.Errorf("매핑 누락 집계 기록 실패: %v", err)
+			}
+		}
+	}()
+}
+
+// Close: 남은 집계를 기록하고 DB 닫기
+func (t *Tracker) Close() error {
+	flushErr := t.Flush()
+	closeErr := t.db.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+// List: 기록된 집계 (since 이후 last_seen, 건수 많은 순). since 가 zero 면 전체.
+func (t *Tracker) List(since time.Time) ([]Entry, error) {
+	from := ""
+	if !since.IsZero() {
+		from = since.Format(timeLayout)
+	}
+	rows, err := t.db.Query(`
+		SELECT ru_param, du, montype, count, first_seen, last_seen
+		FROM unmapped_ru_param
+		WHERE last_seen >= ?
+		ORDER BY count DESC, ru_param, du, montype`, from)
+	if err != nil {
+		return nil, fmt.Errorf("list unmapped: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.RuParam, &e.DU, &e.Montype, &e.Count, &e.FirstSeen, &e.LastSeen); err != nil {
+			return nil, fmt.Errorf("scan unmapped: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list unmapped: %w", err)
+	}
+	return out, nil
+}
+
+// WriteCSV: 집계를 CSV 로 출력. mapped 가 nil 이 아니면 현재 매핑 여부(mapped_now) 열 추가.
+func WriteCSV(w io.Writer, entries []Entry, mapped func(ruParam string) bool) error {
+	cw := csv.NewWriter(w)
+	header := []string{"ru_param", "du", "montype", "count", "first_seen", "last_seen"}
+	if mapped != nil {
+		header = append(header, "mapped_now")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		rec := []string{e.RuParam, e.DU, e.Montype, strconv.FormatInt(e.Count, 10), e.FirstSeen, e.LastSeen}
+		if mapped != nil {
+			rec = append(rec, strconv.FormatBool(mapped(e.RuParam)))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}